@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse"
+)
+
+// APIPath joins escaped path segments onto /api/v1
+func APIPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	return "/api/v1/" + strings.Join(escaped, "/")
+}
+
+// SendJSON makes a request to an API endpoint which go-concourse does not
+// support. The body, if not nil, is sent as JSON and a JSON response is
+// decoded into result, if not nil. It returns false if the endpoint 404s.
+func SendJSON(
+	c concourse.Client,
+	method string,
+	path string,
+	body interface{},
+	result interface{},
+) (bool, error) {
+	var reqBody bytes.Buffer
+
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return false, err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.URL()+path, &reqBody)
+	if err != nil {
+		return false, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode == http.StatusUnauthorized:
+		return false, concourse.ErrUnauthorized
+	case resp.StatusCode == http.StatusForbidden:
+		return false, concourse.ErrForbidden
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return false, fmt.Errorf(
+			"Unexpected response from %s %s: %s %s",
+			method, path, resp.Status, respBody,
+		)
+	}
+
+	if result != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// PipelineParent identifies the job and build whose set_pipeline step last
+// set a pipeline. Both are zero if the pipeline was last set some other way,
+// or if the ATC is too old to report it.
+type PipelineParent struct {
+	JobID   int `json:"parent_job_id,omitempty"`
+	BuildID int `json:"parent_build_id,omitempty"`
+}
+
+// GetPipelineParent looks up which set_pipeline step, if any, last set the
+// pipeline
+func GetPipelineParent(
+	c concourse.Client,
+	teamName string,
+	pipelineName string,
+) (PipelineParent, bool, error) {
+	var parent PipelineParent
+
+	found, err := SendJSON(
+		c, http.MethodGet,
+		APIPath("teams", teamName, "pipelines", pipelineName),
+		nil, &parent,
+	)
+
+	return parent, found, err
+}
+
+// GetWall returns the current wall message, and the time left until it
+// expires. The message is empty if there is no wall. It returns false if the
+// ATC is too old to have a wall.
+func GetWall(c concourse.Client) (atc.Wall, bool, error) {
+	var wall atc.Wall
+	found, err := SendJSON(c, http.MethodGet, APIPath("wall"), nil, &wall)
+	return wall, found, err
+}
+
+// SetWall sets the wall message, which expires after its TTL, if any
+func SetWall(c concourse.Client, wall atc.Wall) (bool, error) {
+	return SendJSON(c, http.MethodPut, APIPath("wall"), wall, nil)
+}
+
+// ClearWall removes the wall message
+func ClearWall(c concourse.Client) (bool, error) {
+	return SendJSON(c, http.MethodDelete, APIPath("wall"), nil, nil)
+}
+
+// RetireWorker retires a worker, which then stops being given new work and
+// is removed once its running builds have finished
+func RetireWorker(c concourse.Client, workerName string) (bool, error) {
+	return SendJSON(c, http.MethodPut, APIPath("workers", workerName, "retire"), nil, nil)
+}
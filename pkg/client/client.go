@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/concourse/concourse/go-concourse/concourse"
 	"golang.org/x/oauth2"
@@ -9,6 +10,9 @@ import (
 
 // NewConcourseClient gives you an authenticated Concourse client using
 // local user username and password authentication. Separate from Basic Auth.
+//
+// The password grant is deferred until the client makes its first request,
+// so creating a client does not contact Concourse.
 func NewConcourseClient(
 	url string,
 	team string,
@@ -25,12 +29,34 @@ func NewConcourseClient(
 
 	ctx := context.Background()
 
-	tok, err := oauth2Config.PasswordCredentialsToken(ctx, username, password)
-	if err != nil {
-		return nil, err
-	}
-	tokenSource := oauth2.StaticTokenSource(tok)
+	tokenSource := oauth2.ReuseTokenSource(nil, passwordTokenSource{
+		ctx:      ctx,
+		config:   oauth2Config,
+		username: username,
+		password: password,
+	})
 	httpClient := oauth2.NewClient(ctx, tokenSource)
 
 	return concourse.NewClient(url, httpClient, true), nil
 }
+
+// NewUnconfiguredClient gives you a Concourse client which fails every
+// request with err, for when the provider has no usable credentials
+func NewUnconfiguredClient(url string, err error) concourse.Client {
+	httpClient := &http.Client{Transport: ErrorTransport{Err: err}}
+
+	return concourse.NewClient(url, httpClient, false)
+}
+
+// passwordTokenSource performs the password grant each time a token is needed
+type passwordTokenSource struct {
+	ctx      context.Context
+	config   oauth2.Config
+	username string
+	password string
+}
+
+// Token fetches a new token using the username and password
+func (s passwordTokenSource) Token() (*oauth2.Token, error) {
+	return s.config.PasswordCredentialsToken(s.ctx, s.username, s.password)
+}
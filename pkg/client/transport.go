@@ -20,3 +20,13 @@ func (t AuthenticatedTransport) RoundTrip(r *http.Request) (*http.Response, erro
 
 	return http.DefaultTransport.RoundTrip(r)
 }
+
+// ErrorTransport is a transport which fails every request with Err
+type ErrorTransport struct {
+	Err error
+}
+
+// RoundTrip fails without sending the request
+func (t ErrorTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return nil, t.Err
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse"
+)
+
+// apiCache holds list responses from concourse for the lifetime of a
+// provider run, so that reading many resources does not repeat the same
+// requests. Anything which writes to concourse must invalidate what it
+// changed.
+type apiCache struct {
+	mu sync.Mutex
+
+	teams     []atc.Team
+	teamsRead bool
+
+	pipelines map[string][]atc.Pipeline
+}
+
+func (c *apiCache) listTeams(client concourse.Client) ([]atc.Team, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.teamsRead {
+		return c.teams, nil
+	}
+
+	teams, err := client.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	c.teams = teams
+	c.teamsRead = true
+
+	return teams, nil
+}
+
+func (c *apiCache) listPipelines(
+	client concourse.Client,
+	teamName string,
+) ([]atc.Pipeline, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pipelines, ok := c.pipelines[teamName]; ok {
+		return pipelines, nil
+	}
+
+	pipelines, err := client.Team(teamName).ListPipelines()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.pipelines == nil {
+		c.pipelines = map[string][]atc.Pipeline{}
+	}
+
+	// an empty team is still a cached answer
+	if pipelines == nil {
+		pipelines = []atc.Pipeline{}
+	}
+
+	c.pipelines[teamName] = pipelines
+
+	return pipelines, nil
+}
+
+func (c *apiCache) invalidateTeams() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.teams = nil
+	c.teamsRead = false
+}
+
+func (c *apiCache) invalidatePipelines(teamName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pipelines, teamName)
+}
+
+// ListTeams lists every team visible to the provider, from the cache if
+// possible
+func (c *ProviderConfig) ListTeams() ([]atc.Team, error) {
+	return c.cache.listTeams(c.Client)
+}
+
+// FindTeam looks up a single team from the cached list of teams
+func (c *ProviderConfig) FindTeam(teamName string) (atc.Team, bool, error) {
+	teams, err := c.ListTeams()
+	if err != nil {
+		return atc.Team{}, false, err
+	}
+
+	for _, team := range teams {
+		if team.Name == teamName {
+			return team, true, nil
+		}
+	}
+
+	return atc.Team{}, false, nil
+}
+
+// ListPipelines lists the pipelines of a team, in their dashboard order,
+// from the cache if possible
+func (c *ProviderConfig) ListPipelines(teamName string) ([]atc.Pipeline, error) {
+	pipelines, err := c.cache.listPipelines(c.Client, teamName)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error listing pipelines within team '%s': %s", teamName, err,
+		)
+	}
+
+	return pipelines, nil
+}
+
+// FindPipeline looks up a single pipeline from the cached list of the
+// team's pipelines
+func (c *ProviderConfig) FindPipeline(
+	teamName string,
+	pipelineName string,
+) (atc.Pipeline, bool, error) {
+	pipelines, err := c.cache.listPipelines(c.Client, teamName)
+	if err != nil {
+		// concourse 404s when listing the pipelines of a missing team, which
+		// means the pipeline is missing too
+		if _, teamFound, teamErr := c.FindTeam(teamName); teamErr == nil && !teamFound {
+			return atc.Pipeline{}, false, nil
+		}
+
+		return atc.Pipeline{}, false, fmt.Errorf(
+			"Error listing pipelines within team '%s': %s", teamName, err,
+		)
+	}
+
+	for _, pipeline := range pipelines {
+		if pipeline.Name == pipelineName {
+			return pipeline, true, nil
+		}
+	}
+
+	return atc.Pipeline{}, false, nil
+}
+
+// InvalidateTeams must be called after creating, renaming, updating or
+// deleting a team
+func (c *ProviderConfig) InvalidateTeams() {
+	c.cache.invalidateTeams()
+}
+
+// InvalidatePipelines must be called after changing any pipeline in a team
+func (c *ProviderConfig) InvalidatePipelines(teamName string) {
+	c.cache.invalidatePipelines(teamName)
+}
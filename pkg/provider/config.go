@@ -12,6 +12,37 @@ import (
 
 type ProviderConfig struct {
 	Client concourse.Client
+
+	// DefaultTeam is used by resources and data sources which omit team_name
+	DefaultTeam string
+
+	// StrictVars is used by concourse_pipeline resources which omit strict_vars
+	StrictVars bool
+
+	// PipelinePolicy is checked against the config of every concourse_pipeline
+	PipelinePolicy PipelinePolicy
+
+	// SkipCredentialsValidation stops plans from contacting concourse to show
+	// extra information, such as the config_diff of a pipeline
+	SkipCredentialsValidation bool
+
+	cache apiCache
+}
+
+// ResolveTeamName returns teamName, or the provider's default team when
+// teamName is empty
+func (c *ProviderConfig) ResolveTeamName(teamName string) (string, error) {
+	if teamName != "" {
+		return teamName, nil
+	}
+
+	if c.DefaultTeam == "" {
+		return "", fmt.Errorf(
+			`"team_name" is not set and the provider has no "default_team" or "team"`,
+		)
+	}
+
+	return c.DefaultTeam, nil
 }
 
 func ProviderConfigurationBuilder(
@@ -19,23 +50,47 @@ func ProviderConfigurationBuilder(
 ) (interface{}, error) {
 
 	targetName := rc.TargetName(d.Get("target").(string))
+	skipValidation := d.Get("skip_credentials_validation").(bool)
+
+	url := d.Get("url").(string)
+	team := d.Get("team").(string)
+	username := d.Get("username").(string)
+	password := d.Get("password").(string)
+
+	providerConfig := &ProviderConfig{
+		DefaultTeam: d.Get("default_team").(string),
+		StrictVars:  d.Get("strict_vars").(bool),
+
+		PipelinePolicy: parsePipelinePolicy(d.Get("pipeline_policy")),
+
+		SkipCredentialsValidation: skipValidation,
+	}
 
 	if targetName != "" {
 		target, err := rc.LoadTarget(targetName, false)
 
 		if err != nil {
-			return nil, fmt.Errorf("Error loading target: %s", err)
+			err = fmt.Errorf("Error loading target: %s", err)
+
+			if !skipValidation {
+				return nil, err
+			}
+
+			providerConfig.Client = client.NewUnconfiguredClient(url, err)
+			return providerConfig, nil
+		}
+
+		if providerConfig.DefaultTeam == "" {
+			providerConfig.DefaultTeam = target.Team().Name()
 		}
 
-		return &ProviderConfig{
-			Client: target.Client(),
-		}, nil
+		providerConfig.Client = target.Client()
+		return providerConfig, nil
 	}
 
-	url := d.Get("url").(string)
-	team := d.Get("team").(string)
-	username := d.Get("username").(string)
-	password := d.Get("password").(string)
+	if providerConfig.DefaultTeam == "" {
+		providerConfig.DefaultTeam = team
+	}
 
 	if url != "" && team != "" && username != "" && password != "" {
 		c, err := client.NewConcourseClient(
@@ -48,12 +103,19 @@ func ProviderConfigurationBuilder(
 			return nil, fmt.Errorf("Error creating client: %s", err)
 		}
 
-		return &ProviderConfig{
-			Client: c,
-		}, nil
+		providerConfig.Client = c
+		return providerConfig, nil
 	}
 
-	return nil, fmt.Errorf(
+	err := fmt.Errorf(
 		`Please specify "target" or "username", "password", "team", and "url"`,
 	)
+
+	if !skipValidation {
+		return nil, err
+	}
+
+	// defer the error until something actually needs to talk to concourse
+	providerConfig.Client = client.NewUnconfiguredClient(url, err)
+	return providerConfig, nil
 }
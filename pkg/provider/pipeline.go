@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
 	"strings"
 
-	"github.com/concourse/concourse/go-concourse/concourse"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -24,7 +27,8 @@ func dataPipeline() *schema.Resource {
 
 			"team_name": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 			},
 
 			"is_exposed": &schema.Schema{
@@ -50,6 +54,59 @@ func dataPipeline() *schema.Resource {
 				Required: false,
 				Computed: true,
 			},
+
+			"config_hash": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: false,
+				Computed: true,
+			},
+
+			"parent_pipeline": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: false,
+				Computed: true,
+			},
+
+			"parent_job": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: false,
+				Computed: true,
+			},
+
+			"url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"badge_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"job_urls": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"job_badge_urls": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"webhooks": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
 	}
 }
@@ -61,6 +118,8 @@ func resourcePipeline() *schema.Resource {
 		UpdateContext: resourcePipelineUpdate,
 		DeleteContext: resourcePipelineDelete,
 
+		CustomizeDiff: resourcePipelineCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -73,7 +132,8 @@ func resourcePipeline() *schema.Resource {
 
 			"team_name": &schema.Schema{
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
 				ForceNew: true,
 			},
 
@@ -90,17 +150,98 @@ func resourcePipeline() *schema.Resource {
 			"pipeline_config_format": &schema.Schema{
 				Type:             schema.TypeString,
 				Required:         true,
-				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"json", "yaml"}, false)),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"json", "yaml", "jsonnet"}, false)),
+				DiffSuppressFunc: pipelineConfigEquivalent,
+			},
+
+			"jsonnet_library_paths": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 
 			"pipeline_config": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: pipelineConfigEquivalent,
 			},
 
 			"vars": &schema.Schema{
-				Type:     schema.TypeMap,
+				Type:      schema.TypeMap,
+				Optional:  true,
+				Sensitive: true,
+			},
+
+			"var_files": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"var_files_hash": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"vars_yaml": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"vars_json"},
+				ValidateDiagFunc: validateVars,
+			},
+
+			"vars_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"vars_yaml"},
+				ValidateDiagFunc: validateVars,
+			},
+
+			"strict_vars": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"true", "false"}, false)),
+			},
+
+			"allowed_unresolved": &schema.Schema{
+				Type:     schema.TypeList,
 				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNamePattern,
+				},
+			},
+
+			"detect_secrets": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(secretDetectionModes, false)),
+			},
+
+			"conflict_strategy": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "fail",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(conflictStrategies, false)),
+			},
+
+			"store_config_hash": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"set_pipeline_parent_check": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "warn",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(setPipelineParentChecks, false)),
 			},
 
 			"json": &schema.Schema{
@@ -112,6 +253,51 @@ func resourcePipeline() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"config_hash": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"config_diff": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"badge_url": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"job_urls": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"job_badge_urls": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"webhooks": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
 	}
 }
@@ -123,9 +309,191 @@ type pipelineHelper struct {
 	IsPaused      bool
 	JSON          string
 	YAML          string
+	ConfigHash    string
 	ConfigVersion string
 }
 
+func validateVars(value interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := ParseVars(value.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid vars",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+
+	return nil
+}
+
+// resourceGetter is satisfied by both schema.ResourceData and
+// schema.ResourceDiff, so that pipeline config can be rendered when planning
+// as well as when applying
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// pipelineConfigKeys are the arguments which affect the rendered config
+var pipelineConfigKeys = []string{
+	"pipeline_config",
+	"pipeline_config_format",
+	"jsonnet_library_paths",
+	"vars",
+	"var_files",
+	"vars_yaml",
+	"vars_json",
+	"strict_vars",
+	"allowed_unresolved",
+	"detect_secrets",
+}
+
+// pipelineVars merges var_files in order, then the structured vars_yaml or
+// vars_json, then the string vars, later ones taking precedence
+func pipelineVars(d resourceGetter) (map[string]interface{}, error) {
+	mergedVars := map[string]interface{}{}
+
+	for _, path := range pipelineVarFiles(d) {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading var file: %s", err)
+		}
+
+		fileVars, err := ParseVars(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing var file %s: %s", path, err)
+		}
+
+		for name, value := range fileVars {
+			mergedVars[name] = value
+		}
+	}
+
+	for _, key := range []string{"vars_yaml", "vars_json"} {
+		structuredVars, err := ParseVars(d.Get(key).(string))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %s", key, err)
+		}
+
+		for name, value := range structuredVars {
+			mergedVars[name] = value
+		}
+	}
+
+	for name, value := range d.Get("vars").(map[string]interface{}) {
+		mergedVars[name] = value
+	}
+
+	return mergedVars, nil
+}
+
+// renderPipelineConfig interpolates vars into pipeline_config and returns it
+// as normalized JSON
+func renderPipelineConfig(d resourceGetter, providerConfig *ProviderConfig) (string, error) {
+	parsedJSON, err := parsePipelineConfigWithVars(
+		d,
+		d.Get("pipeline_config").(string),
+		d.Get("pipeline_config_format").(string),
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	// strict_vars is a string, so that omitting it, unlike setting it to
+	// false, falls back to the provider's default
+	strictVars := providerConfig.StrictVars
+	if v, _ := d.Get("strict_vars").(string); v != "" {
+		strictVars = v == "true"
+	}
+
+	if strictVars {
+		var allowed []string
+		for _, pattern := range d.Get("allowed_unresolved").([]interface{}) {
+			allowed = append(allowed, pattern.(string))
+		}
+
+		if err := CheckUnresolvedVars(parsedJSON, allowed); err != nil {
+			return "", err
+		}
+	}
+
+	// secrets are looked for once the config is normalized to JSON, where
+	// the values of vars are known and can be ignored. Warnings are reported
+	// when the pipeline is read.
+	if d.Get("detect_secrets").(string) == "fail" {
+		vars, err := pipelineVars(d)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := CheckLiteralSecrets(parsedJSON, varValues(vars), "fail"); err != nil {
+			return "", err
+		}
+	}
+
+	return parsedJSON, nil
+}
+
+// parsePipelineConfigWithVars parses the given config with the vars and
+// jsonnet_library_paths of d
+func parsePipelineConfigWithVars(
+	d resourceGetter,
+	pipelineConfig string,
+	pipelineConfigFormat string,
+) (string, error) {
+	vars, err := pipelineVars(d)
+
+	if err != nil {
+		return "", err
+	}
+
+	var jsonnetPaths []string
+	for _, path := range d.Get("jsonnet_library_paths").([]interface{}) {
+		jsonnetPaths = append(jsonnetPaths, path.(string))
+	}
+
+	parsedJSON, err := ParsePipelineConfig(
+		pipelineConfig,
+		pipelineConfigFormat,
+		vars,
+		PipelineConfigOptions{
+			JsonnetPaths: jsonnetPaths,
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("Error parsing pipeline_config: %s", err)
+	}
+
+	return parsedJSON, nil
+}
+
+// pipelineConfigEquivalent suppresses diffs of pipeline_config and
+// pipeline_config_format which do not change the rendered config, such as
+// whitespace, comments, key order or switching between YAML and JSON.
+// Both configs are rendered with the new vars, changes to which are shown
+// against the vars themselves.
+func pipelineConfigEquivalent(k, old, new string, d *schema.ResourceData) bool {
+	oldConfig, newConfig := d.GetChange("pipeline_config")
+	oldFormat, newFormat := d.GetChange("pipeline_config_format")
+
+	if oldConfig.(string) == "" || oldFormat.(string) == "" {
+		return false
+	}
+
+	oldJSON, err := parsePipelineConfigWithVars(d, oldConfig.(string), oldFormat.(string))
+	if err != nil {
+		return false
+	}
+
+	newJSON, err := parsePipelineConfigWithVars(d, newConfig.(string), newFormat.(string))
+	if err != nil {
+		return false
+	}
+
+	return oldJSON == newJSON
+}
+
 func pipelineID(teamName string, pipelineName string) string {
 	return fmt.Sprintf("%s:%s", teamName, pipelineName)
 }
@@ -140,7 +508,7 @@ func parsePipelineID(id string) (string, string, error) {
 
 func readPipeline(
 	ctx context.Context,
-	client concourse.Client,
+	providerConfig *ProviderConfig,
 	teamName string,
 	pipelineName string,
 ) (pipelineHelper, bool, error) {
@@ -151,9 +519,9 @@ func readPipeline(
 		ConfigVersion: "0",
 	}
 
-	team := client.Team(teamName)
+	team := providerConfig.Client.Team(teamName)
 
-	pipeline, pipelineFound, err := team.Pipeline(pipelineName)
+	pipeline, pipelineFound, err := providerConfig.FindPipeline(teamName, pipelineName)
 
 	if err != nil {
 		return retVal, false, err
@@ -200,21 +568,35 @@ func readPipeline(
 		)
 	}
 
+	pipelineCfgHash, err := HashPipelineConfig(pipelineCfgJSON)
+
+	if err != nil {
+		return retVal, false, fmt.Errorf(
+			"Encountered error hashing pipeline %s config within team '%s': %s",
+			pipelineName, teamName, err,
+		)
+	}
+
 	retVal.IsExposed = pipeline.Public
 	retVal.IsPaused = pipeline.Paused
 	retVal.ConfigVersion = version
 	retVal.JSON = pipelineCfgJSON
 	retVal.YAML = pipelineCfgYAML
+	retVal.ConfigHash = pipelineCfgHash
 
 	return retVal, true, nil
 }
 
 func dataPipelineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
 	pipelineName := d.Get("pipeline_name").(string)
-	teamName := d.Get("team_name").(string)
 
-	pipeline, wasFound, err := readPipeline(ctx, client, teamName, pipelineName)
+	teamName, err := providerConfig.ResolveTeamName(d.Get("team_name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipeline, wasFound, err := readPipeline(ctx, providerConfig, teamName, pipelineName)
 
 	if err != nil {
 		return diag.Errorf(
@@ -225,10 +607,36 @@ func dataPipelineRead(ctx context.Context, d *schema.ResourceData, m interface{}
 
 	if wasFound {
 		d.SetId(pipelineID(teamName, pipelineName))
+		d.Set("team_name", teamName)
 		d.Set("is_exposed", pipeline.IsExposed)
 		d.Set("is_paused", pipeline.IsPaused)
 		d.Set("json", pipeline.JSON)
 		d.Set("yaml", pipeline.YAML)
+		d.Set("config_hash", pipeline.ConfigHash)
+
+		if err := setPipelineLinkAttributes(d, providerConfig, pipeline); err != nil {
+			return diag.FromErr(err)
+		}
+
+		// the parent is only informative, so the pipeline is read without it
+		parent, _, err := findSetPipelineParent(providerConfig, teamName, pipelineName)
+		if err != nil {
+			d.Set("parent_pipeline", "")
+			d.Set("parent_job", "")
+
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary: fmt.Sprintf(
+					"Could not look up what set pipeline %s in team '%s'",
+					pipelineName, teamName,
+				),
+				Detail: err.Error(),
+			}}
+		}
+
+		// the parent's team is the pipeline's team
+		d.Set("parent_pipeline", parent.PipelineName)
+		d.Set("parent_job", parent.JobName)
 	} else {
 		d.SetId("")
 	}
@@ -236,18 +644,347 @@ func dataPipelineRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	return nil
 }
 
+func resourcePipelineCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	providerConfig := m.(*ProviderConfig)
+
+	if _, ok := d.GetOk("team_name"); !ok {
+		// team_name was omitted, so show the provider's default team in the plan
+		teamName, err := providerConfig.ResolveTeamName("")
+		if err != nil {
+			return err
+		}
+
+		if err := d.SetNew("team_name", teamName); err != nil {
+			return err
+		}
+	}
+
+	// render the config now, so that mistakes fail the plan not the apply
+	for _, key := range pipelineConfigKeys {
+		if !d.NewValueKnown(key) {
+			return setPipelineConfigUnknown(d)
+		}
+	}
+
+	parsedJSON, err := renderPipelineConfig(d, providerConfig)
+	if err != nil {
+		return err
+	}
+
+	// warnings are reported when the pipeline is read, as plans cannot show
+	// them from here
+	if d.NewValueKnown("team_name") {
+		_, err := CheckPipelinePolicy(
+			providerConfig.PipelinePolicy,
+			d.Get("team_name").(string),
+			d.Get("pipeline_name").(string),
+			parsedJSON,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	varFilesHash, err := hashVarFiles(pipelineVarFiles(d))
+	if err != nil {
+		return err
+	}
+
+	if varFilesHash != d.Get("var_files_hash").(string) {
+		if err := d.SetNew("var_files_hash", varFilesHash); err != nil {
+			return err
+		}
+	}
+
+	if err := setPipelineConfigDiff(ctx, d, providerConfig, parsedJSON); err != nil {
+		return err
+	}
+
+	if err := setPipelineLinks(d, providerConfig, parsedJSON); err != nil {
+		return err
+	}
+
+	if err := refuseSetPipelineParent(d, providerConfig); err != nil {
+		return err
+	}
+
+	if !d.Get("store_config_hash").(bool) {
+		return nil
+	}
+
+	// without the rendered config in state, detect drift by its hash instead
+	configHash, err := HashPipelineConfig(parsedJSON)
+	if err != nil {
+		return err
+	}
+
+	if configHash != d.Get("config_hash").(string) {
+		return d.SetNewComputed("config_hash")
+	}
+
+	return nil
+}
+
+// pipelineConfigDerivedKeys are the attributes which CustomizeDiff works out
+// from the rendered config
+var pipelineConfigDerivedKeys = []string{
+	"config_diff",
+	"var_files_hash",
+	"job_urls",
+	"job_badge_urls",
+	"webhooks",
+}
+
+// setPipelineConfigUnknown marks everything worked out from the rendered
+// config as unknown, when the config cannot be rendered until apply, so that
+// resources using them are not planned with stale values
+func setPipelineConfigUnknown(d *schema.ResourceDiff) error {
+	keys := pipelineConfigDerivedKeys
+	if d.Get("store_config_hash").(bool) {
+		keys = append(keys, "config_hash")
+	}
+
+	for _, key := range keys {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setPipelineConfigDiff shows the changes between the pipeline's config in
+// concourse, as last refreshed, and the rendered config in the plan. Only the
+// names of what changed are shown if vars, which may be secrets, were or will
+// be interpolated, or without the rendered config in state.
+func setPipelineConfigDiff(
+	ctx context.Context,
+	d *schema.ResourceDiff,
+	providerConfig *ProviderConfig,
+	parsedJSON string,
+) error {
+	normalizedJSON, err := NormalizePipelineConfig(parsedJSON)
+	if err != nil {
+		return err
+	}
+
+	currentJSON := d.Get("json").(string)
+
+	if d.Id() != "" && currentJSON == "" {
+		currentJSON, err = currentPipelineJSON(ctx, d, providerConfig, normalizedJSON)
+		if err != nil {
+			// the diff is only informative, so the plan goes ahead without it
+			log.Printf("[WARN] Not showing config_diff of pipeline %s: %s", d.Id(), err)
+			return nil
+		}
+	}
+
+	changes, configDiff, err := DiffPipelineConfigs(currentJSON, normalizedJSON)
+	if err != nil {
+		return err
+	}
+
+	if d.Get("store_config_hash").(bool) || pipelineHasVars(d) {
+		configDiff = SummarizePipelineConfigChanges(changes)
+	}
+
+	if configDiff == d.Get("config_diff").(string) {
+		return nil
+	}
+
+	return d.SetNew("config_diff", configDiff)
+}
+
+// currentPipelineJSON returns the config of a pipeline whose rendered config
+// is not in state. It is only looked up if the hash of the pipeline's config
+// differs from that of normalizedJSON, and never without credentials.
+func currentPipelineJSON(
+	ctx context.Context,
+	d *schema.ResourceDiff,
+	providerConfig *ProviderConfig,
+	normalizedJSON string,
+) (string, error) {
+	configHash, err := HashPipelineConfig(normalizedJSON)
+	if err != nil {
+		return "", err
+	}
+
+	if configHash == d.Get("config_hash").(string) {
+		return normalizedJSON, nil
+	}
+
+	if providerConfig.SkipCredentialsValidation {
+		return "", fmt.Errorf("skip_credentials_validation is set")
+	}
+
+	teamName, pipelineName, err := parsePipelineID(d.Id())
+	if err != nil {
+		return "", err
+	}
+
+	pipeline, _, err := readPipeline(ctx, providerConfig, teamName, pipelineName)
+	if err != nil {
+		return "", err
+	}
+
+	return pipeline.JSON, nil
+}
+
+// pipelineHasVars is whether any vars were, or will be, interpolated into
+// the pipeline's config
+func pipelineHasVars(d *schema.ResourceDiff) bool {
+	for _, key := range []string{"vars", "var_files", "vars_yaml", "vars_json"} {
+		oldValue, newValue := d.GetChange(key)
+
+		for _, value := range []interface{}{oldValue, newValue} {
+			switch typedValue := value.(type) {
+			case string:
+				if typedValue != "" {
+					return true
+				}
+			case []interface{}:
+				if len(typedValue) != 0 {
+					return true
+				}
+			case map[string]interface{}:
+				if len(typedValue) != 0 {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// refuseSetPipelineParent fails the plan of a pipeline whose config would be
+// set while it is managed by a set_pipeline step, when
+// set_pipeline_parent_check is "fail". Only refusals can be shown when
+// planning, warnings are shown on apply.
+func refuseSetPipelineParent(d *schema.ResourceDiff, providerConfig *ProviderConfig) error {
+	if d.Get("set_pipeline_parent_check").(string) != "fail" || !d.NewValueKnown("team_name") {
+		return nil
+	}
+
+	configChanged := d.Id() == "" ||
+		d.HasChanges(pipelineConfigKeys...) ||
+		d.HasChanges("var_files_hash", "config_hash", "config_diff")
+
+	if !configChanged || providerConfig.SkipCredentialsValidation {
+		return nil
+	}
+
+	teamName := d.Get("team_name").(string)
+	pipelineName := d.Get("pipeline_name").(string)
+
+	if d.Id() != "" {
+		var err error
+		if teamName, pipelineName, err = parsePipelineID(d.Id()); err != nil {
+			return err
+		}
+	}
+
+	parent, found, err := findSetPipelineParent(providerConfig, teamName, pipelineName)
+	if err != nil {
+		// the check is repeated when applying, which fails if it still errors
+		log.Printf("[WARN] Error looking up what set pipeline %s in team '%s': %s", pipelineName, teamName, err)
+		return nil
+	}
+
+	if !found {
+		return nil
+	}
+
+	refusal := setPipelineParentDiagnostic(teamName, pipelineName, parent, diag.Error)
+	return fmt.Errorf("%s: %s", refusal.Summary, refusal.Detail)
+}
+
+// setPipelineLinks shows the URLs of the pipeline, its jobs and webhooks in
+// the plan, so that they can be used by other resources
+func setPipelineLinks(
+	d *schema.ResourceDiff,
+	providerConfig *ProviderConfig,
+	parsedJSON string,
+) error {
+	if !d.NewValueKnown("team_name") || !d.NewValueKnown("pipeline_name") {
+		for _, key := range pipelineLinkKeys {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	links, err := PipelineLinks(
+		providerConfig.Client.URL(),
+		d.Get("team_name").(string),
+		d.Get("pipeline_name").(string),
+		parsedJSON,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range pipelineLinkKeys {
+		current := d.Get(key)
+
+		if currentMap, ok := current.(map[string]interface{}); ok {
+			stringMap := map[string]string{}
+			for k, v := range currentMap {
+				stringMap[k] = v.(string)
+			}
+			current = stringMap
+		}
+
+		if reflect.DeepEqual(current, links[key]) {
+			continue
+		}
+
+		if err := d.SetNew(key, links[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setPipelineLinkAttributes sets the attributes returned by PipelineLinks
+func setPipelineLinkAttributes(
+	d *schema.ResourceData,
+	providerConfig *ProviderConfig,
+	pipeline pipelineHelper,
+) error {
+	links, err := PipelineLinks(
+		providerConfig.Client.URL(), pipeline.TeamName, pipeline.PipelineName, pipeline.JSON,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range pipelineLinkKeys {
+		d.Set(key, links[key])
+	}
+
+	return nil
+}
+
 func resourcePipelineCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	return resourcePipelineUpdate(ctx, d, m)
+	return resourcePipelineCreateUpdate(ctx, d, m, true)
+}
+
+func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourcePipelineCreateUpdate(ctx, d, m, false)
 }
 
 func resourcePipelineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
 	teamName, pipelineName, err := parsePipelineID(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	pipeline, wasFound, err := readPipeline(ctx, client, teamName, pipelineName)
+	pipeline, wasFound, err := readPipeline(ctx, providerConfig, teamName, pipelineName)
 
 	if err != nil {
 		return diag.Errorf(
@@ -256,25 +993,56 @@ func resourcePipelineRead(ctx context.Context, d *schema.ResourceData, m interfa
 		)
 	}
 
+	var diags diag.Diagnostics
+
 	if wasFound {
+		diags = pipelinePolicyWarnings(providerConfig, teamName, pipelineName, pipeline.JSON)
+		diags = append(diags, pipelineSecretWarnings(d, pipeline.JSON)...)
+
 		d.SetId(pipelineID(pipeline.TeamName, pipeline.PipelineName))
 		d.Set("team_name", pipeline.TeamName)
 		d.Set("pipeline_name", pipeline.PipelineName)
 		d.Set("is_exposed", pipeline.IsExposed)
 		d.Set("is_paused", pipeline.IsPaused)
-		d.Set("json", pipeline.JSON)
-		d.Set("yaml", pipeline.YAML)
+		d.Set("config_hash", pipeline.ConfigHash)
+
+		if err := setPipelineLinkAttributes(d, providerConfig, pipeline); err != nil {
+			return diag.FromErr(err)
+		}
+
+		// the rendered config may contain interpolated secrets
+		if d.Get("store_config_hash").(bool) {
+			d.Set("json", "")
+			d.Set("yaml", "")
+		} else {
+			d.Set("json", pipeline.JSON)
+			d.Set("yaml", pipeline.YAML)
+		}
+
+		// the config in concourse is now what is in state, so there is no
+		// difference to show until the next plan
+		d.Set("config_diff", "")
+
+		// imported pipelines have no state for arguments with defaults
+		if _, ok := d.GetOk("conflict_strategy"); !ok {
+			d.Set("conflict_strategy", "fail")
+		}
+
+		if _, ok := d.GetOk("set_pipeline_parent_check"); !ok {
+			d.Set("set_pipeline_parent_check", "warn")
+		}
 	} else {
 		d.SetId("")
 	}
 
-	return nil
+	return diags
 }
 
-func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+func resourcePipelineCreateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}, create bool) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	client := providerConfig.Client
 
-	if d.HasChange("pipeline_name") && d.Id() != "" {
+	if d.HasChange("pipeline_name") && !create {
 		teamName := strings.SplitN(d.Id(), ":", 2)[0]
 		oldPipelineName := strings.SplitN(d.Id(), ":", 2)[1]
 		newPipelineName := d.Get("pipeline_name").(string)
@@ -282,6 +1050,7 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		team := client.Team(teamName)
 
 		_, warnings, err := team.RenamePipeline(oldPipelineName, newPipelineName)
+		providerConfig.InvalidatePipelines(teamName)
 
 		if err != nil {
 			return diag.Errorf(
@@ -294,13 +1063,70 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 	pipelineName := d.Get("pipeline_name").(string)
 	teamName := d.Get("team_name").(string)
 	d.SetId(pipelineID(teamName, pipelineName))
-	team := client.Team(teamName)
 
-	pipelineConfig := d.Get("pipeline_config").(string)
-	pipelineConfigFormat := d.Get("pipeline_config_format").(string)
-	vars := d.Get("vars").(map[string]interface{})
+	// each of these is only sent when it changed, so that e.g. pausing a
+	// pipeline does not bump its config version
+	var diags diag.Diagnostics
 
-	pipeline, _, err := readPipeline(ctx, client, teamName, pipelineName)
+	if create || d.HasChanges(pipelineConfigKeys...) || d.HasChanges("var_files_hash", "config_hash", "config_diff") {
+		diags = checkSetPipelineParent(
+			providerConfig, teamName, pipelineName, d.Get("set_pipeline_parent_check").(string),
+		)
+		if diags.HasError() {
+			return diags
+		}
+
+		diags = append(diags, setPipelineConfig(ctx, d, providerConfig, teamName, pipelineName)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if create || d.HasChange("is_exposed") {
+		if diags := setPipelineExposed(d, providerConfig, teamName, pipelineName); diags != nil {
+			return diags
+		}
+	}
+
+	if create || d.HasChange("is_paused") {
+		if diags := setPipelinePaused(d, providerConfig, teamName, pipelineName); diags != nil {
+			return diags
+		}
+	}
+
+	return append(diags, resourcePipelineRead(ctx, d, m)...)
+}
+
+func setPipelineConfig(
+	ctx context.Context,
+	d resourceGetter,
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+) diag.Diagnostics {
+	team := providerConfig.Client.Team(teamName)
+
+	parsedJSON, err := renderPipelineConfig(d, providerConfig)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// warnings are reported when the pipeline is read
+	if _, err := CheckPipelinePolicy(
+		providerConfig.PipelinePolicy, teamName, pipelineName, parsedJSON,
+	); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// config read from concourse is normalized, so that defaults which are
+	// spelled out, e.g. serial: false, do not look like changes
+	normalizedJSON, err := NormalizePipelineConfig(parsedJSON)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipeline, pipelineFound, err := readPipeline(ctx, providerConfig, teamName, pipelineName)
 
 	if err != nil {
 		return diag.Errorf(
@@ -309,15 +1135,58 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		)
 	}
 
-	parsedJSON, err := ParsePipelineConfig(pipelineConfig, pipelineConfigFormat, vars)
+	if pipelineFound && pipeline.JSON == normalizedJSON {
+		return nil
+	}
 
+	changes, _, err := DiffPipelineConfigs(pipeline.JSON, normalizedJSON)
 	if err != nil {
-		return diag.Errorf("Error parsing pipeline_config: %s", err)
+		return diag.FromErr(err)
 	}
 
 	_, _, configWarnings, err := team.CreateOrUpdatePipelineConfig(
 		pipelineName, pipeline.ConfigVersion, []byte(parsedJSON), false,
 	)
+	providerConfig.InvalidatePipelines(teamName)
+
+	// the pipeline was changed between reading its version and setting it
+	for retries := 0; isConfigVersionConflict(err); retries++ {
+		if d.Get("conflict_strategy").(string) != "retry" || retries == pipelineConfigConflictRetries {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary: fmt.Sprintf(
+					"Conflict setting config for pipeline %s in team '%s'",
+					pipelineName, teamName,
+				),
+				Detail: describePipelineConflict(
+					providerConfig, teamName, pipelineName, pipeline.ConfigVersion,
+				),
+			}}
+		}
+
+		pipeline, pipelineFound, err = readPipeline(ctx, providerConfig, teamName, pipelineName)
+
+		if err != nil {
+			return diag.Errorf(
+				"Error looking up pipeline %s in team %s: %s",
+				pipelineName, teamName, err,
+			)
+		}
+
+		if pipelineFound && pipeline.JSON == normalizedJSON {
+			return nil
+		}
+
+		changes, _, err = DiffPipelineConfigs(pipeline.JSON, normalizedJSON)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		_, _, configWarnings, err = team.CreateOrUpdatePipelineConfig(
+			pipelineName, pipeline.ConfigVersion, []byte(parsedJSON), false,
+		)
+		providerConfig.InvalidatePipelines(teamName)
+	}
 
 	if err != nil {
 		return diag.Errorf(
@@ -338,6 +1207,31 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		)
 	}
 
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary: fmt.Sprintf(
+			"Changed config for pipeline %s in team '%s'",
+			pipelineName, teamName,
+		),
+		Detail: SummarizePipelineConfigChanges(changes),
+	}}
+}
+
+func setPipelineExposed(
+	d resourceGetter,
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+) diag.Diagnostics {
+	team := providerConfig.Client.Team(teamName)
+
+	// is_exposed is part of the cached list of pipelines
+	defer providerConfig.InvalidatePipelines(teamName)
+
 	if d.Get("is_exposed").(bool) {
 		found, err := team.ExposePipeline(pipelineName)
 		if err != nil {
@@ -368,6 +1262,20 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		}
 	}
 
+	return nil
+}
+
+func setPipelinePaused(
+	d resourceGetter,
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+) diag.Diagnostics {
+	team := providerConfig.Client.Team(teamName)
+
+	// is_paused is part of the cached list of pipelines
+	defer providerConfig.InvalidatePipelines(teamName)
+
 	if d.Get("is_paused").(bool) {
 		found, err := team.PausePipeline(pipelineName)
 		if err != nil {
@@ -398,16 +1306,17 @@ func resourcePipelineUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		}
 	}
 
-	return resourcePipelineRead(ctx, d, m)
+	return nil
 }
 
 func resourcePipelineDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
 	pipelineName := d.Get("pipeline_name").(string)
 	teamName := d.Get("team_name").(string)
-	team := client.Team(teamName)
+	team := providerConfig.Client.Team(teamName)
 
 	deleted, err := team.DeletePipeline(pipelineName)
+	providerConfig.InvalidatePipelines(teamName)
 
 	if err != nil {
 		return diag.Errorf(
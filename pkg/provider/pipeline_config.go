@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataPipelineConfig() *schema.Resource {
+	namedType := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"type": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+
+	return &schema.Resource{
+		ReadContext: dataPipelineConfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"pipeline_config_format": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"json", "yaml", "jsonnet"}, false)),
+			},
+
+			"jsonnet_library_paths": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"pipeline_config": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"vars": &schema.Schema{
+				Type:      schema.TypeMap,
+				Optional:  true,
+				Sensitive: true,
+			},
+
+			"var_files": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"vars_yaml": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"vars_json"},
+				ValidateDiagFunc: validateVars,
+			},
+
+			"vars_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"vars_yaml"},
+				ValidateDiagFunc: validateVars,
+			},
+
+			"strict_vars": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"true", "false"}, false)),
+			},
+
+			"allowed_unresolved": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNamePattern,
+				},
+			},
+
+			"detect_secrets": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(secretDetectionModes, false)),
+			},
+
+			// the rendered config may contain interpolated secrets
+			"json": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"yaml": &schema.Schema{
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"config_hash": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"job_names": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"resources": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     namedType,
+			},
+
+			"resource_types": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     namedType,
+			},
+
+			"groups": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"jobs": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+
+						"resources": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataPipelineConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+
+	parsedJSON, err := renderPipelineConfig(d, providerConfig)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var config atc.Config
+	if err := json.Unmarshal([]byte(parsedJSON), &config); err != nil {
+		return diag.Errorf("Error parsing pipeline_config: %s", err)
+	}
+
+	pipelineYAML, err := JSONToYAML(parsedJSON)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	configHash, err := HashPipelineConfig(parsedJSON)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	jobNames := []string{}
+	for _, job := range config.Jobs {
+		jobNames = append(jobNames, job.Name)
+	}
+
+	resources := []map[string]interface{}{}
+	for _, resource := range config.Resources {
+		resources = append(resources, map[string]interface{}{
+			"name": resource.Name,
+			"type": resource.Type,
+		})
+	}
+
+	resourceTypes := []map[string]interface{}{}
+	for _, resourceType := range config.ResourceTypes {
+		resourceTypes = append(resourceTypes, map[string]interface{}{
+			"name": resourceType.Name,
+			"type": resourceType.Type,
+		})
+	}
+
+	groups := []map[string]interface{}{}
+	for _, group := range config.Groups {
+		groups = append(groups, map[string]interface{}{
+			"name":      group.Name,
+			"jobs":      group.Jobs,
+			"resources": group.Resources,
+		})
+	}
+
+	d.SetId(configHash)
+	d.Set("json", parsedJSON)
+	d.Set("yaml", pipelineYAML)
+	d.Set("config_hash", configHash)
+	d.Set("job_names", jobNames)
+	d.Set("resources", resources)
+	d.Set("resource_types", resourceTypes)
+	d.Set("groups", groups)
+
+	return pipelineSecretWarnings(d, parsedJSON)
+}
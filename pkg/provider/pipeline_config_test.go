@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPipelineConfigRead(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataPipelineConfig().Schema, map[string]interface{}{
+		"pipeline_config_format": "yaml",
+		"pipeline_config": `---
+resource_types:
+- name: slack
+  type: registry-image
+resources:
+- name: repo
+  type: git
+  source: {uri: ((uri))}
+- name: notify
+  type: slack
+jobs:
+- name: test
+  plan: [{get: repo}]
+- name: deploy
+  plan: [{get: repo, passed: [test]}, {put: notify}]
+groups:
+- name: all
+  jobs: [test, deploy]
+`,
+		"vars": map[string]interface{}{"uri": "https://example.com/repo.git"},
+	})
+
+	if diags := dataPipelineConfigRead(context.Background(), d, &ProviderConfig{}); diags.HasError() {
+		t.Fatalf("error reading pipeline config: %v", diags)
+	}
+
+	if actual := d.Get("job_names"); !reflect.DeepEqual(actual, []interface{}{"test", "deploy"}) {
+		t.Fatalf("unexpected job_names: %v", actual)
+	}
+
+	expectedResources := []interface{}{
+		map[string]interface{}{"name": "repo", "type": "git"},
+		map[string]interface{}{"name": "notify", "type": "slack"},
+	}
+	if actual := d.Get("resources"); !reflect.DeepEqual(actual, expectedResources) {
+		t.Fatalf("unexpected resources: %v", actual)
+	}
+
+	expectedGroups := []interface{}{
+		map[string]interface{}{
+			"name":      "all",
+			"jobs":      []interface{}{"test", "deploy"},
+			"resources": []interface{}{},
+		},
+	}
+	if actual := d.Get("groups"); !reflect.DeepEqual(actual, expectedGroups) {
+		t.Fatalf("unexpected groups: %v", actual)
+	}
+
+	if d.Id() == "" || d.Id() != d.Get("config_hash") {
+		t.Fatalf("expected id to be the config hash, got %q", d.Id())
+	}
+}
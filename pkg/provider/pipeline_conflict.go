@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var conflictStrategies = []string{
+	"fail",
+	"retry",
+}
+
+// pipelineConfigConflictRetries bounds how many times the "retry" conflict
+// strategy re-reads and re-sends a pipeline's config
+const pipelineConfigConflictRetries = 3
+
+// isConfigVersionConflict reports whether the ATC rejected a pipeline config
+// because the config version sent with it is no longer the latest. The ATC
+// reports this as an internal server error, so match on the message.
+func isConfigVersionConflict(err error) bool {
+	return err != nil && strings.Contains(
+		err.Error(), "comparison with existing config failed during save",
+	)
+}
+
+// describePipelineConflict explains who changed a pipeline after terraform
+// read its config version
+func describePipelineConflict(
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+	readVersion string,
+) string {
+	providerConfig.InvalidatePipelines(teamName)
+
+	var detail strings.Builder
+
+	fmt.Fprintf(
+		&detail,
+		"Pipeline %s in team '%s' was changed by something else after terraform read config version %s.",
+		pipelineName, teamName, readVersion,
+	)
+
+	pipeline, found, err := providerConfig.FindPipeline(teamName, pipelineName)
+	if err == nil && found && pipeline.LastUpdated != 0 {
+		fmt.Fprintf(
+			&detail, " It was last updated at %s.",
+			time.Unix(pipeline.LastUpdated, 0).UTC().Format(time.RFC3339),
+		)
+	}
+
+	parent, found, err := findSetPipelineParent(providerConfig, teamName, pipelineName)
+
+	if err != nil || !found {
+		detail.WriteString(
+			" It was not set by a set_pipeline step, so was probably set with fly set-pipeline or another terraform run.",
+		)
+	} else {
+		fmt.Fprintf(&detail, " It was set by a set_pipeline step in %s.", parent)
+	}
+
+	detail.WriteString(
+		` Check that change before applying again, or set conflict_strategy = "retry" to overwrite changes made elsewhere.`,
+	)
+
+	return detail.String()
+}
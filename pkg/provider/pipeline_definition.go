@@ -0,0 +1,423 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// definitionKind is the type of an attribute of a pipeline definition block
+type definitionKind int
+
+const (
+	definitionString definitionKind = iota
+	definitionRequiredString
+	definitionBool
+	definitionInt
+	definitionStringList
+	definitionStringMap
+
+	// definitionJSON attributes hold nested values which terraform's schema
+	// cannot describe, encoded with jsonencode
+	definitionJSON
+)
+
+func (kind definitionKind) schema() *schema.Schema {
+	switch kind {
+	case definitionRequiredString:
+		return &schema.Schema{Type: schema.TypeString, Required: true}
+	case definitionBool:
+		return &schema.Schema{Type: schema.TypeBool, Optional: true}
+	case definitionInt:
+		return &schema.Schema{Type: schema.TypeInt, Optional: true}
+	case definitionStringList:
+		return &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		}
+	case definitionStringMap:
+		return &schema.Schema{
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		}
+	case definitionJSON:
+		return &schema.Schema{
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+		}
+	default:
+		return &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+}
+
+// definitionField is an attribute of a pipeline definition block, which is
+// rendered as the key of the same name in pipeline config
+type definitionField struct {
+	Name string
+	Kind definitionKind
+}
+
+var resourceTypeFields = []definitionField{
+	{"name", definitionRequiredString},
+	{"type", definitionRequiredString},
+	{"source", definitionJSON},
+	{"privileged", definitionBool},
+	{"check_every", definitionString},
+	{"tags", definitionStringList},
+	{"params", definitionJSON},
+	{"unique_version_history", definitionBool},
+}
+
+var resourceFields = []definitionField{
+	{"name", definitionRequiredString},
+	{"old_name", definitionString},
+	{"type", definitionRequiredString},
+	{"source", definitionJSON},
+	{"public", definitionBool},
+	{"webhook_token", definitionString},
+	{"check_every", definitionString},
+	{"check_timeout", definitionString},
+	{"tags", definitionStringList},
+	{"version", definitionJSON},
+	{"icon", definitionString},
+}
+
+var jobFields = []definitionField{
+	{"name", definitionRequiredString},
+	{"old_name", definitionString},
+	{"public", definitionBool},
+	{"disable_manual_trigger", definitionBool},
+	{"serial", definitionBool},
+	{"interruptible", definitionBool},
+	{"serial_groups", definitionStringList},
+	{"max_in_flight", definitionInt},
+	{"build_logs_to_retain", definitionInt},
+}
+
+var groupFields = []definitionField{
+	{"name", definitionRequiredString},
+	{"jobs", definitionStringList},
+	{"resources", definitionStringList},
+}
+
+var stepFields = []definitionField{
+	{"get", definitionString},
+	{"put", definitionString},
+	{"task", definitionString},
+	{"set_pipeline", definitionString},
+	{"load_var", definitionString},
+	{"resource", definitionString},
+	{"trigger", definitionBool},
+	{"passed", definitionStringList},
+	{"version", definitionString},
+	{"params", definitionJSON},
+	{"inputs", definitionStringList},
+	{"get_params", definitionJSON},
+	{"file", definitionString},
+	{"image", definitionString},
+	{"privileged", definitionBool},
+	{"config", definitionJSON},
+	{"vars", definitionJSON},
+	{"var_files", definitionStringList},
+	{"team", definitionString},
+	{"format", definitionString},
+	{"reveal", definitionBool},
+	{"input_mapping", definitionStringMap},
+	{"output_mapping", definitionStringMap},
+	{"timeout", definitionString},
+	{"attempts", definitionInt},
+	{"tags", definitionStringList},
+}
+
+var jobHooks = []string{
+	"on_success",
+	"on_failure",
+	"on_abort",
+	"on_error",
+	"ensure",
+}
+
+// definitionSchema is the schema of a block with the given fields. Blocks
+// with extra also have a JSON "extra" attribute, for anything else.
+func definitionSchema(fields []definitionField, extra bool) map[string]*schema.Schema {
+	blockSchema := map[string]*schema.Schema{}
+
+	for _, field := range fields {
+		blockSchema[field.Name] = field.Kind.schema()
+	}
+
+	if extra {
+		blockSchema["extra"] = definitionJSON.schema()
+	}
+
+	return blockSchema
+}
+
+// definitionStep is a step of a plan, whose "extra" is for e.g. in_parallel,
+// do, try or hooks
+func definitionStep() *schema.Resource {
+	return &schema.Resource{
+		Schema: definitionSchema(stepFields, true),
+	}
+}
+
+func dataPipelineDefinition() *schema.Resource {
+	jobSchema := definitionSchema(jobFields, true)
+
+	jobSchema["plan"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Required: true,
+		Elem:     definitionStep(),
+	}
+
+	for _, hook := range jobHooks {
+		jobSchema[hook] = &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     definitionStep(),
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: dataPipelineDefinitionRead,
+
+		Schema: map[string]*schema.Schema{
+			"resource_type": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: definitionSchema(resourceTypeFields, true),
+				},
+			},
+
+			"resource": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: definitionSchema(resourceFields, true),
+				},
+			},
+
+			"job": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: jobSchema,
+				},
+			},
+
+			"group": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: definitionSchema(groupFields, false),
+				},
+			},
+
+			"json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"yaml": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// renderDefinitionBlock renders the configured fields of a block, leaving
+// out unset ones as concourse's own config does. Attributes which are JSON
+// encoded are decoded, and "extra" is merged over the top.
+func renderDefinitionBlock(
+	block map[string]interface{},
+	fields []definitionField,
+	path string,
+) (map[string]interface{}, error) {
+	rendered := map[string]interface{}{}
+
+	for _, field := range fields {
+		value, ok := block[field.Name]
+		if !ok {
+			continue
+		}
+
+		switch typedValue := value.(type) {
+		case string:
+			if typedValue == "" {
+				continue
+			}
+
+			if field.Kind == definitionJSON {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(typedValue), &decoded); err != nil {
+					return nil, fmt.Errorf("%s.%s is not valid JSON: %s", path, field.Name, err)
+				}
+				rendered[field.Name] = decoded
+				continue
+			}
+
+		case bool:
+			if !typedValue {
+				continue
+			}
+
+		case int:
+			if typedValue == 0 {
+				continue
+			}
+
+		case []interface{}:
+			if len(typedValue) == 0 {
+				continue
+			}
+
+		case map[string]interface{}:
+			if len(typedValue) == 0 {
+				continue
+			}
+		}
+
+		rendered[field.Name] = value
+	}
+
+	if extra, ok := block["extra"].(string); ok && extra != "" {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(extra), &decoded); err != nil {
+			return nil, fmt.Errorf("%s.extra is not a JSON object: %s", path, err)
+		}
+
+		for key, value := range decoded {
+			rendered[key] = value
+		}
+	}
+
+	return rendered, nil
+}
+
+func renderDefinitionBlocks(
+	blocks []interface{},
+	fields []definitionField,
+	path string,
+	render func(map[string]interface{}, map[string]interface{}, string) error,
+) ([]interface{}, error) {
+	var rendered []interface{}
+
+	for i, block := range blocks {
+		blockPath := fmt.Sprintf("%s[%d]", path, i)
+		blockMap := block.(map[string]interface{})
+
+		renderedBlock, err := renderDefinitionBlock(blockMap, fields, blockPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if render != nil {
+			if err := render(blockMap, renderedBlock, blockPath); err != nil {
+				return nil, err
+			}
+		}
+
+		rendered = append(rendered, renderedBlock)
+	}
+
+	return rendered, nil
+}
+
+func renderDefinitionJob(
+	job map[string]interface{},
+	rendered map[string]interface{},
+	path string,
+) error {
+	plan, err := renderDefinitionBlocks(
+		job["plan"].([]interface{}), stepFields, path+".plan", nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	rendered["plan"] = plan
+
+	for _, hook := range jobHooks {
+		steps, err := renderDefinitionBlocks(
+			job[hook].([]interface{}), stepFields, path+"."+hook, nil,
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(steps) > 0 {
+			rendered[hook] = steps[0]
+		}
+	}
+
+	return nil
+}
+
+func dataPipelineDefinitionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	config := map[string]interface{}{}
+
+	sections := []struct {
+		Block  string
+		Key    string
+		Fields []definitionField
+		Render func(map[string]interface{}, map[string]interface{}, string) error
+	}{
+		{"resource_type", "resource_types", resourceTypeFields, nil},
+		{"resource", "resources", resourceFields, nil},
+		{"job", "jobs", jobFields, renderDefinitionJob},
+		{"group", "groups", groupFields, nil},
+	}
+
+	for _, section := range sections {
+		rendered, err := renderDefinitionBlocks(
+			d.Get(section.Block).([]interface{}), section.Fields, section.Block, section.Render,
+		)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if len(rendered) > 0 {
+			config[section.Key] = rendered
+		}
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipelineJSON, err := JSONToJSON(string(configJSON))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pipelineYAML, err := JSONToYAML(pipelineJSON)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	configHash, err := HashPipelineConfig(pipelineJSON)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(configHash)
+	d.Set("json", pipelineJSON)
+	d.Set("yaml", pipelineYAML)
+
+	return nil
+}
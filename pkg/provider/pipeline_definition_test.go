@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPipelineDefinitionRead(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataPipelineDefinition().Schema, map[string]interface{}{
+		"resource": []interface{}{
+			map[string]interface{}{
+				"name":   "every-midnight",
+				"type":   "time",
+				"source": `{"location":"Europe/London","start":"12:00AM","stop":"12:15AM"}`,
+			},
+		},
+		"job": []interface{}{
+			map[string]interface{}{
+				"name":   "check-the-time",
+				"serial": true,
+				"plan": []interface{}{
+					map[string]interface{}{
+						"get":     "every-midnight",
+						"trigger": true,
+					},
+					map[string]interface{}{
+						"task":  "say-hello",
+						"extra": `{"config":{"platform":"linux","run":{"path":"echo"}}}`,
+					},
+				},
+				"ensure": []interface{}{
+					map[string]interface{}{
+						"put":    "every-midnight",
+						"params": `{"foo":"bar"}`,
+					},
+				},
+			},
+		},
+	})
+
+	if diags := dataPipelineDefinitionRead(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("error reading pipeline definition: %v", diags)
+	}
+
+	expected := `{"jobs":[{"ensure":{"params":{"foo":"bar"},"put":"every-midnight"},"name":"check-the-time","plan":[{"get":"every-midnight","trigger":true},{"config":{"platform":"linux","run":{"path":"echo"}},"task":"say-hello"}],"serial":true}],"resources":[{"name":"every-midnight","source":{"location":"Europe/London","start":"12:00AM","stop":"12:15AM"},"type":"time"}]}`
+
+	if actual := d.Get("json").(string); expected != actual {
+		t.Fatalf("\n\nexpected:\n\n%s\n\ngot:\n\n%s\n\n", expected, actual)
+	}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aryann/difflib"
+	"github.com/ghodss/yaml"
+)
+
+// PipelineConfigChange is a group, var source, resource, resource type or job
+// which was added, removed or changed between two pipeline configs
+type PipelineConfigChange struct {
+	Kind   string
+	Name   string
+	Action string
+}
+
+func (c PipelineConfigChange) String() string {
+	if c.Action == "changed" {
+		return fmt.Sprintf("%s %s has changed", c.Kind, c.Name)
+	}
+
+	return fmt.Sprintf("%s %s has been %s", c.Kind, c.Name, c.Action)
+}
+
+// pipelineDiffSections are the parts of pipeline config which are diffed, in
+// the order fly set-pipeline shows them
+var pipelineDiffSections = []struct {
+	Key     string
+	Kind    string
+	Heading string
+}{
+	{"groups", "group", "groups"},
+	{"var_sources", "variable source", "variable sources"},
+	{"resources", "resource", "resources"},
+	{"resource_types", "resource type", "resource types"},
+	{"jobs", "job", "jobs"},
+}
+
+// DiffPipelineConfigs compares two pipeline configs, each of which is JSON
+// (or "" for no config), item by item as fly set-pipeline does. It returns
+// the changes and a rendering of them as a line by line diff of their YAML.
+func DiffPipelineConfigs(beforeJSON string, afterJSON string) ([]PipelineConfigChange, string, error) {
+	before, err := pipelineDiffItems(beforeJSON)
+	if err != nil {
+		return nil, "", err
+	}
+
+	after, err := pipelineDiffItems(afterJSON)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var changes []PipelineConfigChange
+	var rendered strings.Builder
+
+	for _, section := range pipelineDiffSections {
+		var sectionChanges []PipelineConfigChange
+		var sectionRendered strings.Builder
+
+		afterByName := map[string]interface{}{}
+		for _, item := range after[section.Key] {
+			afterByName[pipelineDiffItemName(item)] = item
+		}
+
+		beforeByName := map[string]interface{}{}
+		for _, item := range before[section.Key] {
+			name := pipelineDiffItemName(item)
+			beforeByName[name] = item
+
+			afterItem, found := afterByName[name]
+			if !found {
+				change := PipelineConfigChange{section.Kind, name, "removed"}
+				sectionChanges = append(sectionChanges, change)
+				renderPipelineDiffItem(&sectionRendered, change, item, nil)
+				continue
+			}
+
+			beforeYAML, _ := yaml.Marshal(item)
+			afterYAML, _ := yaml.Marshal(afterItem)
+
+			if string(beforeYAML) != string(afterYAML) {
+				change := PipelineConfigChange{section.Kind, name, "changed"}
+				sectionChanges = append(sectionChanges, change)
+				renderPipelineDiffItem(&sectionRendered, change, item, afterItem)
+			}
+		}
+
+		for _, item := range after[section.Key] {
+			name := pipelineDiffItemName(item)
+
+			if _, found := beforeByName[name]; !found {
+				change := PipelineConfigChange{section.Kind, name, "added"}
+				sectionChanges = append(sectionChanges, change)
+				renderPipelineDiffItem(&sectionRendered, change, nil, item)
+			}
+		}
+
+		if len(sectionChanges) > 0 {
+			changes = append(changes, sectionChanges...)
+			fmt.Fprintf(&rendered, "%s:\n%s", section.Heading, sectionRendered.String())
+		}
+	}
+
+	return changes, rendered.String(), nil
+}
+
+// SummarizePipelineConfigChanges lists changes one per line
+func SummarizePipelineConfigChanges(changes []PipelineConfigChange) string {
+	var summary strings.Builder
+
+	for _, change := range changes {
+		fmt.Fprintf(&summary, "  - %s\n", change)
+	}
+
+	return summary.String()
+}
+
+func pipelineDiffItems(configJSON string) (map[string][]interface{}, error) {
+	items := map[string][]interface{}{}
+
+	if configJSON == "" {
+		return items, nil
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, err
+	}
+
+	for _, section := range pipelineDiffSections {
+		list, _ := config[section.Key].([]interface{})
+		items[section.Key] = list
+	}
+
+	return items, nil
+}
+
+func pipelineDiffItemName(item interface{}) string {
+	itemMap, _ := item.(map[string]interface{})
+	name, _ := itemMap["name"].(string)
+	return name
+}
+
+func renderPipelineDiffItem(
+	to *strings.Builder,
+	change PipelineConfigChange,
+	before interface{},
+	after interface{},
+) {
+	fmt.Fprintf(to, "  %s:\n", change)
+
+	beforeLines := pipelineDiffLines(before)
+	afterLines := pipelineDiffLines(after)
+
+	for _, line := range diffLines(beforeLines, afterLines) {
+		fmt.Fprintf(to, "  %s\n", line)
+	}
+}
+
+func pipelineDiffLines(item interface{}) []string {
+	if item == nil {
+		return nil
+	}
+
+	itemYAML, _ := yaml.Marshal(item)
+	return strings.Split(strings.TrimSuffix(string(itemYAML), "\n"), "\n")
+}
+
+// diffLines returns a line by line diff of a and b, with each line prefixed
+// by "-", "+" or " "
+func diffLines(a []string, b []string) []string {
+	var lines []string
+
+	for _, diff := range difflib.Diff(a, b) {
+		switch diff.Delta {
+		case difflib.LeftOnly:
+			lines = append(lines, "- "+diff.Payload)
+		case difflib.RightOnly:
+			lines = append(lines, "+ "+diff.Payload)
+		case difflib.Common:
+			lines = append(lines, "  "+diff.Payload)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// namedConfigLists are the top level lists of pipeline config whose items are
+// identified by their name
+var namedConfigLists = []string{
+	"groups",
+	"var_sources",
+	"resources",
+	"resource_types",
+	"jobs",
+}
+
+// SplitYAMLDocuments splits a multi-document YAML stream into its documents,
+// dropping any which are empty. Each document is decoded, so that "---"
+// markers followed by comments or content are recognised, and encoded again.
+func SplitYAMLDocuments(inputYAML string) ([]string, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(inputYAML))
+
+	var documents []string
+
+	for i := 1; ; i++ {
+		var document interface{}
+
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %s", i, err)
+		}
+
+		if document == nil {
+			continue
+		}
+
+		documentYAML, err := yaml.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %s", i, err)
+		}
+
+		documents = append(documents, string(documentYAML))
+	}
+
+	return documents, nil
+}
+
+// MergePipelineConfigs deep merges fragments of pipeline config, each of
+// which is JSON. The items of namedConfigLists are concatenated and must
+// have unique names, other maps are merged recursively and any other values
+// are taken from the last fragment which sets them.
+func MergePipelineConfigs(fragmentsJSON []string) (string, error) {
+	merged := map[string]interface{}{}
+
+	// where each named item came from, e.g. seen["jobs"]["build"] = 2
+	seen := map[string]map[string]int{}
+	for _, key := range namedConfigLists {
+		seen[key] = map[string]int{}
+	}
+
+	for i, fragmentJSON := range fragmentsJSON {
+		var fragment map[string]interface{}
+
+		if err := json.Unmarshal([]byte(fragmentJSON), &fragment); err != nil {
+			return "", fmt.Errorf("document %d is not a map: %s", i+1, err)
+		}
+
+		for key, value := range fragment {
+			if _, ok := seen[key]; !ok {
+				merged[key] = deepMerge(merged[key], value)
+				continue
+			}
+
+			items, ok := value.([]interface{})
+			if !ok {
+				return "", fmt.Errorf("%s in document %d is not a list", key, i+1)
+			}
+
+			existing, _ := merged[key].([]interface{})
+
+			for j, item := range items {
+				itemMap, _ := item.(map[string]interface{})
+				name, _ := itemMap["name"].(string)
+				if name == "" {
+					return "", fmt.Errorf("%s[%d] in document %d has no name", key, j, i+1)
+				}
+
+				if previous, ok := seen[key][name]; ok {
+					return "", fmt.Errorf(
+						"%s %q is defined in both document %d and document %d",
+						key, name, previous, i+1,
+					)
+				}
+
+				seen[key][name] = i + 1
+				existing = append(existing, item)
+			}
+
+			merged[key] = existing
+		}
+	}
+
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+
+	return string(mergedJSON), nil
+}
+
+func deepMerge(base interface{}, override interface{}) interface{} {
+	baseMap, baseOK := base.(map[string]interface{})
+	overrideMap, overrideOK := override.(map[string]interface{})
+
+	if !baseOK || !overrideOK {
+		return override
+	}
+
+	for key, value := range overrideMap {
+		baseMap[key] = deepMerge(baseMap[key], value)
+	}
+
+	return baseMap
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParsePipelineConfigMergesDocuments(t *testing.T) {
+	pipelineConfig := `
+resources:
+- name: every-midnight
+  type: time
+  source: {location: ((location))}
+--- # jobs
+jobs:
+- name: check-the-time
+  plan:
+  - get: every-midnight
+---
+resources:
+- name: repo
+  type: git
+--- {groups: [{name: all, jobs: [check-the-time]}]}
+`
+
+	expected := `{"groups":[{"jobs":["check-the-time"],"name":"all"}],"jobs":[{"name":"check-the-time","plan":[{"get":"every-midnight"}]}],"resources":[{"name":"every-midnight","source":{"location":"Europe/London"},"type":"time"},{"name":"repo","type":"git"}]}`
+
+	actual, err := ParsePipelineConfig(
+		pipelineConfig, "yaml",
+		map[string]interface{}{"location": "Europe/London"},
+		PipelineConfigOptions{},
+	)
+
+	if err != nil {
+		t.Fatalf("error parsing pipeline config: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("\n\nexpected:\n\n%s\n\ngot:\n\n%s\n\n", expected, actual)
+	}
+}
+
+func TestParsePipelineConfigSkipsEmptyDocuments(t *testing.T) {
+	pipelineConfig := "---\n---\njobs:\n- name: build\n  plan: [{get: repo}]\n"
+
+	expected := `{"jobs":[{"name":"build","plan":[{"get":"repo"}]}]}`
+
+	actual, err := ParsePipelineConfig(pipelineConfig, "yaml", nil, PipelineConfigOptions{})
+
+	if err != nil {
+		t.Fatalf("error parsing pipeline config: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("\n\nexpected:\n\n%s\n\ngot:\n\n%s\n\n", expected, actual)
+	}
+}
+
+func TestMergePipelineConfigsRejectsDuplicateNames(t *testing.T) {
+	_, err := MergePipelineConfigs([]string{
+		`{"jobs":[{"name":"build"}]}`,
+		`{"resources":[{"name":"repo"}]}`,
+		`{"jobs":[{"name":"test"},{"name":"build"}]}`,
+	})
+
+	expected := `jobs "build" is defined in both document 1 and document 3`
+
+	if err == nil || err.Error() != expected {
+		t.Fatalf("\n\nexpected error:\n\n%s\n\ngot:\n\n%v\n\n", expected, err)
+	}
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourcePipelineOrder() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePipelineOrderCreateUpdate,
+		ReadContext:   resourcePipelineOrderRead,
+		UpdateContext: resourcePipelineOrderCreateUpdate,
+		DeleteContext: resourcePipelineOrderDelete,
+
+		CustomizeDiff: resourcePipelineOrderCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"team_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"pipelines": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNamePattern,
+				},
+			},
+
+			"ordered_pipelines": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// OrderPipelines orders pipeline names by the first of patterns, see
+// nameMatches, which each matches, keeping the current order of names which match the same pattern.
+// Names which match no pattern are appended, also in their current order.
+func OrderPipelines(patterns []string, names []string) []string {
+	ordered := []string{}
+	placed := map[string]bool{}
+
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+
+			if nameMatches(name, pattern) {
+				ordered = append(ordered, name)
+				placed[name] = true
+			}
+		}
+	}
+
+	for _, name := range names {
+		if !placed[name] {
+			ordered = append(ordered, name)
+		}
+	}
+
+	return ordered
+}
+
+func pipelineOrderPatterns(d resourceGetter) []string {
+	var patterns []string
+	for _, pattern := range d.Get("pipelines").([]interface{}) {
+		patterns = append(patterns, pattern.(string))
+	}
+	return patterns
+}
+
+func listPipelineNames(providerConfig *ProviderConfig, teamName string) ([]string, error) {
+	pipelines, err := providerConfig.ListPipelines(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, pipeline := range pipelines {
+		names = append(names, pipeline.Name)
+	}
+
+	return names, nil
+}
+
+func resourcePipelineOrderCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	providerConfig := m.(*ProviderConfig)
+
+	if _, ok := d.GetOk("team_name"); !ok {
+		teamName, err := providerConfig.ResolveTeamName("")
+		if err != nil {
+			return err
+		}
+
+		if err := d.SetNew("team_name", teamName); err != nil {
+			return err
+		}
+	}
+
+	if d.Id() == "" || !d.NewValueKnown("pipelines") {
+		return nil
+	}
+
+	// the pipelines were reordered, or added to, outside of terraform
+	current := []string{}
+	for _, name := range d.Get("ordered_pipelines").([]interface{}) {
+		current = append(current, name.(string))
+	}
+
+	if !reflect.DeepEqual(OrderPipelines(pipelineOrderPatterns(d), current), current) {
+		return d.SetNewComputed("ordered_pipelines")
+	}
+
+	return nil
+}
+
+func resourcePipelineOrderCreateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Get("team_name").(string)
+
+	names, err := listPipelineNames(providerConfig, teamName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(teamName)
+
+	ordered := OrderPipelines(pipelineOrderPatterns(d), names)
+
+	if !reflect.DeepEqual(ordered, names) {
+		err := providerConfig.Client.Team(teamName).OrderingPipelines(ordered)
+		providerConfig.InvalidatePipelines(teamName)
+
+		if err != nil {
+			return diag.Errorf(
+				"Error ordering pipelines within team '%s': %s", teamName, err,
+			)
+		}
+	}
+
+	return resourcePipelineOrderRead(ctx, d, m)
+}
+
+func resourcePipelineOrderRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Id()
+
+	_, teamFound, err := providerConfig.FindTeam(teamName)
+	if err != nil {
+		return diag.Errorf("Error looking up team '%s': %s", teamName, err)
+	}
+
+	if !teamFound {
+		d.SetId("")
+		return nil
+	}
+
+	names, err := listPipelineNames(providerConfig, teamName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("team_name", teamName)
+	d.Set("ordered_pipelines", names)
+
+	return nil
+}
+
+func resourcePipelineOrderDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// pipelines keep their order, as there is nothing to restore it to
+	d.SetId("")
+	return nil
+}
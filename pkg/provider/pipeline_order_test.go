@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderPipelines(t *testing.T) {
+	names := []string{"zeta", "deploy-prod", "build", "deploy-staging", "alpha"}
+	patterns := []string{"build", "deploy-*", "missing"}
+
+	expected := []string{"build", "deploy-prod", "deploy-staging", "zeta", "alpha"}
+
+	if actual := OrderPipelines(patterns, names); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("\n\nexpected:\n\n%v\n\ngot:\n\n%v\n\n", expected, actual)
+	}
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	"github.com/alphagov/terraform-provider-concourse/pkg/client"
+)
+
+var setPipelineParentChecks = []string{
+	"warn",
+	"fail",
+	"ignore",
+}
+
+// setPipelineParent is the build whose set_pipeline step last set a pipeline.
+// Only BuildID is known if the build could not be read.
+type setPipelineParent struct {
+	BuildID      int
+	BuildName    string
+	TeamName     string
+	PipelineName string
+	JobName      string
+}
+
+func (p setPipelineParent) String() string {
+	if p.PipelineName == "" {
+		return fmt.Sprintf("build %d", p.BuildID)
+	}
+
+	return fmt.Sprintf(
+		"build %s/%s #%s of team '%s'",
+		p.PipelineName, p.JobName, p.BuildName, p.TeamName,
+	)
+}
+
+// findSetPipelineParent looks up the set_pipeline step, if any, which last
+// set a pipeline
+func findSetPipelineParent(
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+) (setPipelineParent, bool, error) {
+	parent, found, err := client.GetPipelineParent(
+		providerConfig.Client, teamName, pipelineName,
+	)
+
+	if err != nil || !found || parent.BuildID == 0 {
+		return setPipelineParent{}, false, err
+	}
+
+	setBy := setPipelineParent{BuildID: parent.BuildID}
+
+	build, found, err := providerConfig.Client.Build(strconv.Itoa(parent.BuildID))
+	if err == nil && found {
+		setBy.BuildName = build.Name
+		setBy.TeamName = build.TeamName
+		setBy.PipelineName = build.PipelineName
+		setBy.JobName = build.JobName
+	}
+
+	return setBy, true, nil
+}
+
+// checkSetPipelineParent reports a pipeline which was last set by a
+// set_pipeline step, as terraform and that step will keep overwriting each
+// other. check is "warn", "fail" or "ignore".
+func checkSetPipelineParent(
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+	check string,
+) diag.Diagnostics {
+	if check == "ignore" {
+		return nil
+	}
+
+	parent, found, err := findSetPipelineParent(providerConfig, teamName, pipelineName)
+	if err != nil {
+		return diag.Errorf(
+			"Error looking up what set pipeline %s in team '%s': %s",
+			pipelineName, teamName, err,
+		)
+	}
+
+	if !found {
+		return nil
+	}
+
+	severity := diag.Warning
+	if check == "fail" {
+		severity = diag.Error
+	}
+
+	return diag.Diagnostics{setPipelineParentDiagnostic(teamName, pipelineName, parent, severity)}
+}
+
+// setPipelineParentDiagnostic reports that a pipeline is managed by the
+// set_pipeline step of parent
+func setPipelineParentDiagnostic(
+	teamName string,
+	pipelineName string,
+	parent setPipelineParent,
+	severity diag.Severity,
+) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: severity,
+		Summary: fmt.Sprintf(
+			"Pipeline %s in team '%s' is managed by a set_pipeline step",
+			pipelineName, teamName,
+		),
+		Detail: fmt.Sprintf(
+			"It was last set by a set_pipeline step in %s, which will overwrite changes made by terraform, and the other way around. "+
+				`Remove the pipeline from one of them, or set set_pipeline_parent_check = "ignore".`,
+			parent,
+		),
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/concourse/concourse/go-concourse/concourse"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/alphagov/terraform-provider-concourse/pkg/client"
+)
+
+func TestCheckSetPipelineParent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v1/teams/main/pipelines/child":
+			w.Write([]byte(`{"name": "child", "parent_build_id": 42, "parent_job_id": 7}`))
+		case "/api/v1/teams/main/pipelines/standalone":
+			w.Write([]byte(`{"name": "standalone"}`))
+		case "/api/v1/builds/42":
+			w.Write([]byte(`{"id": 42, "name": "3", "team_name": "main", "pipeline_name": "parent", "job_name": "set-children"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	providerConfig := &ProviderConfig{
+		Client: concourse.NewClient(server.URL, http.DefaultClient, false),
+	}
+
+	if diags := checkSetPipelineParent(providerConfig, "main", "standalone", "fail"); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics for a pipeline set by fly, got %v", diags)
+	}
+
+	diags := checkSetPipelineParent(providerConfig, "main", "child", "warn")
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a warning for a child pipeline, got %v", diags)
+	}
+
+	if !strings.Contains(diags[0].Detail, "build parent/set-children #3 of team 'main'") {
+		t.Fatalf("expected the warning to name the parent build, got %q", diags[0].Detail)
+	}
+
+	if diags := checkSetPipelineParent(providerConfig, "main", "child", "fail"); !diags.HasError() {
+		t.Fatalf("expected an error for a child pipeline, got %v", diags)
+	}
+
+	if diags := checkSetPipelineParent(providerConfig, "main", "child", "ignore"); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics when ignored, got %v", diags)
+	}
+}
+
+func TestSetPipelineParentCheckAllowsOfflinePlans(t *testing.T) {
+	providerConfig := &ProviderConfig{
+		DefaultTeam:               "main",
+		Client:                    client.NewUnconfiguredClient("https://ci.example.com", fmt.Errorf("no credentials")),
+		SkipCredentialsValidation: true,
+	}
+
+	_, err := resourcePipeline().Diff(
+		context.Background(),
+		nil,
+		terraform.NewResourceConfigRaw(map[string]interface{}{
+			"pipeline_name":             "child",
+			"is_exposed":                false,
+			"is_paused":                 false,
+			"pipeline_config_format":    "yaml",
+			"pipeline_config":           "jobs:\n- name: build\n  plan: [{get: repo}]\n",
+			"set_pipeline_parent_check": "fail",
+		}),
+		providerConfig,
+	)
+
+	if err != nil {
+		t.Fatalf("expected an offline plan to succeed, got %s", err)
+	}
+}
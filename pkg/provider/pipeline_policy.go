@@ -0,0 +1,438 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var policySeverities = []string{"error", "warning"}
+
+// PipelinePolicy is a set of rules which every concourse_pipeline's config
+// is checked against when planning. A nil rule is not checked.
+type PipelinePolicy struct {
+	AllowedResourceTypes *AllowedResourceTypesRule
+	AllowedRegistries    *AllowedRegistriesRule
+	RequireSerial        *PolicyRule
+	DenyPrivileged       *DenyPrivilegedRule
+}
+
+// PolicyRule is the part common to every rule
+type PolicyRule struct {
+	// Severity is "error", which fails the plan, or "warning"
+	Severity string
+}
+
+// AllowedResourceTypesRule requires the type of every resource and resource
+// type to be one of Types, which may end with "*" to match a prefix
+type AllowedResourceTypesRule struct {
+	PolicyRule
+	Types []string
+}
+
+// AllowedRegistriesRule requires every image, of resource types, tasks and
+// image resources, to come from one of Registries. Images without a registry
+// come from docker.io.
+type AllowedRegistriesRule struct {
+	PolicyRule
+	Registries []string
+}
+
+// DenyPrivilegedRule forbids privileged tasks and resource types, except in
+// the pipelines of ExceptTeams
+type DenyPrivilegedRule struct {
+	PolicyRule
+	ExceptTeams []string
+}
+
+// PolicyViolation is a part of a pipeline's config which breaks a rule
+type PolicyViolation struct {
+	Rule     string
+	Severity string
+	Path     string
+	Message  string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: %s at %s", v.Rule, v.Message, v.Path)
+}
+
+func pipelinePolicyRuleSchema(attributes map[string]*schema.Schema) *schema.Schema {
+	attributes["severity"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "error",
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(policySeverities, false)),
+	}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: attributes,
+		},
+	}
+}
+
+func pipelinePolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "Rules which the config of every concourse_pipeline must follow",
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"allowed_resource_types": pipelinePolicyRuleSchema(map[string]*schema.Schema{
+					"types": {
+						Type:     schema.TypeList,
+						Required: true,
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validateNamePattern,
+						},
+					},
+				}),
+
+				"allowed_registries": pipelinePolicyRuleSchema(map[string]*schema.Schema{
+					"registries": {
+						Type:     schema.TypeList,
+						Required: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				}),
+
+				"require_serial_or_max_in_flight": pipelinePolicyRuleSchema(map[string]*schema.Schema{}),
+
+				"deny_privileged": pipelinePolicyRuleSchema(map[string]*schema.Schema{
+					"except_teams": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				}),
+			},
+		},
+	}
+}
+
+func policyStrings(value interface{}) []string {
+	var strs []string
+	for _, str := range value.([]interface{}) {
+		strs = append(strs, str.(string))
+	}
+	return strs
+}
+
+// parsePipelinePolicy reads the provider's pipeline_policy block
+func parsePipelinePolicy(value interface{}) PipelinePolicy {
+	var policy PipelinePolicy
+
+	blocks := value.([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return policy
+	}
+
+	block := blocks[0].(map[string]interface{})
+
+	rule := func(key string) (map[string]interface{}, PolicyRule, bool) {
+		rules := block[key].([]interface{})
+		if len(rules) == 0 {
+			return nil, PolicyRule{}, false
+		}
+
+		// a block with no attributes set is nil
+		attributes, _ := rules[0].(map[string]interface{})
+		if attributes == nil {
+			return map[string]interface{}{}, PolicyRule{Severity: "error"}, true
+		}
+
+		return attributes, PolicyRule{Severity: attributes["severity"].(string)}, true
+	}
+
+	if attributes, common, ok := rule("allowed_resource_types"); ok {
+		policy.AllowedResourceTypes = &AllowedResourceTypesRule{
+			PolicyRule: common,
+			Types:      policyStrings(attributes["types"]),
+		}
+	}
+
+	if attributes, common, ok := rule("allowed_registries"); ok {
+		policy.AllowedRegistries = &AllowedRegistriesRule{
+			PolicyRule: common,
+			Registries: policyStrings(attributes["registries"]),
+		}
+	}
+
+	if _, common, ok := rule("require_serial_or_max_in_flight"); ok {
+		policy.RequireSerial = &common
+	}
+
+	if attributes, common, ok := rule("deny_privileged"); ok {
+		var exceptTeams []string
+		if teams, ok := attributes["except_teams"]; ok {
+			exceptTeams = policyStrings(teams)
+		}
+
+		policy.DenyPrivileged = &DenyPrivilegedRule{
+			PolicyRule:  common,
+			ExceptTeams: exceptTeams,
+		}
+	}
+
+	return policy
+}
+
+// Evaluate checks the parsed config of a pipeline in teamName against every
+// rule of the policy
+func (p PipelinePolicy) Evaluate(teamName string, parsedJSON string) ([]PolicyViolation, error) {
+	var config map[string]interface{}
+
+	if err := json.Unmarshal([]byte(parsedJSON), &config); err != nil {
+		return nil, err
+	}
+
+	var violations []PolicyViolation
+
+	if rule := p.AllowedResourceTypes; rule != nil {
+		for _, key := range []string{"resources", "resource_types"} {
+			for i, item := range policyItems(config[key]) {
+				resourceType, _ := item["type"].(string)
+
+				if !nameAllowed(resourceType, rule.Types) {
+					violations = append(violations, PolicyViolation{
+						Rule:     "allowed_resource_types",
+						Severity: rule.Severity,
+						Path:     fmt.Sprintf("%s[%d].type", key, i),
+						Message:  fmt.Sprintf("type %q of %q is not allowed", resourceType, item["name"]),
+					})
+				}
+			}
+		}
+	}
+
+	if rule := p.AllowedRegistries; rule != nil {
+		for _, image := range policyImages(config) {
+			registry := imageRegistry(image.Repository)
+
+			if !stringInList(registry, rule.Registries) {
+				violations = append(violations, PolicyViolation{
+					Rule:     "allowed_registries",
+					Severity: rule.Severity,
+					Path:     image.Path,
+					Message:  fmt.Sprintf("image %q is from registry %q, which is not allowed", image.Repository, registry),
+				})
+			}
+		}
+	}
+
+	if rule := p.RequireSerial; rule != nil {
+		for i, job := range policyItems(config["jobs"]) {
+			serial, _ := job["serial"].(bool)
+			serialGroups, _ := job["serial_groups"].([]interface{})
+			_, maxInFlight := job["max_in_flight"]
+
+			if !serial && len(serialGroups) == 0 && !maxInFlight {
+				violations = append(violations, PolicyViolation{
+					Rule:     "require_serial_or_max_in_flight",
+					Severity: rule.Severity,
+					Path:     fmt.Sprintf("jobs[%d]", i),
+					Message:  fmt.Sprintf("job %q sets neither serial, serial_groups nor max_in_flight", job["name"]),
+				})
+			}
+		}
+	}
+
+	if rule := p.DenyPrivileged; rule != nil && !stringInList(teamName, rule.ExceptTeams) {
+		for i, resourceType := range policyItems(config["resource_types"]) {
+			if privileged, _ := resourceType["privileged"].(bool); privileged {
+				violations = append(violations, PolicyViolation{
+					Rule:     "deny_privileged",
+					Severity: rule.Severity,
+					Path:     fmt.Sprintf("resource_types[%d].privileged", i),
+					Message:  fmt.Sprintf("resource type %q is privileged", resourceType["name"]),
+				})
+			}
+		}
+
+		WalkJSON(config["jobs"], func(path string, value interface{}) {
+			step, ok := value.(map[string]interface{})
+			if !ok {
+				return
+			}
+
+			if _, isTask := step["task"]; !isTask {
+				return
+			}
+
+			if privileged, _ := step["privileged"].(bool); privileged {
+				violations = append(violations, PolicyViolation{
+					Rule:     "deny_privileged",
+					Severity: rule.Severity,
+					Path:     "jobs" + path + ".privileged",
+					Message:  fmt.Sprintf("task %q is privileged", step["task"]),
+				})
+			}
+		})
+	}
+
+	return violations, nil
+}
+
+// policyImage is an image repository used by a pipeline
+type policyImage struct {
+	Path       string
+	Repository string
+}
+
+var imageResourceTypes = []string{"registry-image", "docker-image"}
+
+func policyImages(config map[string]interface{}) []policyImage {
+	var images []policyImage
+
+	for i, resourceType := range policyItems(config["resource_types"]) {
+		if image, ok := imageRepository(resourceType); ok {
+			images = append(images, policyImage{
+				Path:       fmt.Sprintf("resource_types[%d].source.repository", i),
+				Repository: image,
+			})
+		}
+	}
+
+	for i, resource := range policyItems(config["resources"]) {
+		if image, ok := imageRepository(resource); ok {
+			images = append(images, policyImage{
+				Path:       fmt.Sprintf("resources[%d].source.repository", i),
+				Repository: image,
+			})
+		}
+	}
+
+	// the image_resource of tasks, which may be nested in other steps
+	WalkJSON(config["jobs"], func(path string, value interface{}) {
+		imageResource, ok := value.(map[string]interface{})
+		if !ok || !strings.HasSuffix(path, ".image_resource") {
+			return
+		}
+
+		if image, ok := imageRepository(imageResource); ok {
+			images = append(images, policyImage{
+				Path:       "jobs" + path + ".source.repository",
+				Repository: image,
+			})
+		}
+	})
+
+	return images
+}
+
+// imageRepository returns the repository of a resource, resource type or
+// image resource whose type is an image registry
+func imageRepository(item map[string]interface{}) (string, bool) {
+	itemType, _ := item["type"].(string)
+	if !stringInList(itemType, imageResourceTypes) {
+		return "", false
+	}
+
+	source, _ := item["source"].(map[string]interface{})
+	repository, _ := source["repository"].(string)
+
+	return repository, repository != ""
+}
+
+// imageRegistry returns the registry of an image repository, as docker does
+func imageRegistry(repository string) string {
+	parts := strings.SplitN(repository, "/", 2)
+
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+
+	return "docker.io"
+}
+
+func policyItems(value interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		itemMap, _ := item.(map[string]interface{})
+		items = append(items, itemMap)
+	}
+
+	return items
+}
+
+func stringInList(str string, list []string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckPipelinePolicy evaluates the provider's pipeline policy, returning an
+// error listing every violation of a rule whose severity is "error", and a
+// warning for every other violation
+func CheckPipelinePolicy(
+	policy PipelinePolicy,
+	teamName string,
+	pipelineName string,
+	parsedJSON string,
+) (diag.Diagnostics, error) {
+	violations, err := policy.Evaluate(teamName, parsedJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var errors []string
+	var warnings diag.Diagnostics
+
+	for _, violation := range violations {
+		if violation.Severity == "error" {
+			errors = append(errors, fmt.Sprintf("  - %s", violation))
+			continue
+		}
+
+		warnings = append(warnings, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary: fmt.Sprintf(
+				"Pipeline %s in team '%s' breaks pipeline_policy rule %s",
+				pipelineName, teamName, violation.Rule,
+			),
+			Detail: fmt.Sprintf("%s at %s", violation.Message, violation.Path),
+		})
+	}
+
+	if len(errors) > 0 {
+		return warnings, fmt.Errorf(
+			"pipeline %s in team '%s' breaks pipeline_policy:\n%s",
+			pipelineName, teamName, strings.Join(errors, "\n"),
+		)
+	}
+
+	return warnings, nil
+}
+
+// pipelinePolicyWarnings returns the warnings of the provider's pipeline
+// policy for a pipeline's config. Reads report them, as plans cannot show
+// warnings from CustomizeDiff. Violations of rules with a severity of
+// "error" are left to fail the plan instead.
+func pipelinePolicyWarnings(
+	providerConfig *ProviderConfig,
+	teamName string,
+	pipelineName string,
+	configJSON string,
+) diag.Diagnostics {
+	warnings, _ := CheckPipelinePolicy(
+		providerConfig.PipelinePolicy, teamName, pipelineName, configJSON,
+	)
+
+	return warnings
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const policyPipelineJSON = `{
+  "resource_types": [
+    {"name": "slack", "type": "registry-image", "privileged": true,
+     "source": {"repository": "cfcommunity/slack-notification-resource"}}
+  ],
+  "resources": [
+    {"name": "repo", "type": "git"},
+    {"name": "notify", "type": "slack"}
+  ],
+  "jobs": [
+    {"name": "build", "serial": true, "plan": [
+      {"get": "repo"},
+      {"in_parallel": {"steps": [
+        {"task": "docker", "privileged": true, "config": {
+          "image_resource": {"type": "registry-image", "source": {"repository": "registry.example.com/builder"}}
+        }}
+      ]}}
+    ]},
+    {"name": "deploy", "plan": [{"put": "notify"}]}
+  ]
+}`
+
+func TestPipelinePolicyEvaluate(t *testing.T) {
+	policy := PipelinePolicy{
+		AllowedResourceTypes: &AllowedResourceTypesRule{
+			PolicyRule: PolicyRule{Severity: "error"},
+			Types:      []string{"git", "registry-image"},
+		},
+		AllowedRegistries: &AllowedRegistriesRule{
+			PolicyRule: PolicyRule{Severity: "warning"},
+			Registries: []string{"registry.example.com"},
+		},
+		RequireSerial: &PolicyRule{Severity: "error"},
+		DenyPrivileged: &DenyPrivilegedRule{
+			PolicyRule:  PolicyRule{Severity: "error"},
+			ExceptTeams: []string{"ops"},
+		},
+	}
+
+	violations, err := policy.Evaluate("main", policyPipelineJSON)
+	if err != nil {
+		t.Fatalf("error evaluating policy: %s", err)
+	}
+
+	var paths []string
+	for _, violation := range violations {
+		paths = append(paths, violation.Rule+" "+violation.Path)
+	}
+
+	expected := []string{
+		"allowed_resource_types resources[1].type",
+		"allowed_registries resource_types[0].source.repository",
+		"require_serial_or_max_in_flight jobs[1]",
+		"deny_privileged resource_types[0].privileged",
+		"deny_privileged jobs[0].plan[1].in_parallel.steps[0].privileged",
+	}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("\n\nexpected:\n\n%v\n\ngot:\n\n%v\n\n", expected, paths)
+	}
+
+	violations, err = policy.Evaluate("ops", policyPipelineJSON)
+	if err != nil {
+		t.Fatalf("error evaluating policy: %s", err)
+	}
+
+	for _, violation := range violations {
+		if violation.Rule == "deny_privileged" {
+			t.Fatalf("expected team ops to be allowed privileged tasks, got %s", violation)
+		}
+	}
+}
+
+func TestPipelineReadReportsPolicyWarnings(t *testing.T) {
+	providerConfig := &ProviderConfig{
+		DefaultTeam: "main",
+		Client:      fakePipelineClient(buildJobConfig),
+		PipelinePolicy: PipelinePolicy{
+			RequireSerial: &PolicyRule{Severity: "warning"},
+		},
+	}
+
+	d := resourcePipeline().TestResourceData()
+	d.SetId("main:my-pipeline")
+
+	diags := resourcePipelineRead(context.Background(), d, providerConfig)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a policy warning, got %v", diags)
+	}
+
+	if !strings.Contains(diags[0].Summary, "require_serial_or_max_in_flight") {
+		t.Fatalf("expected the warning to name the rule, got %q", diags[0].Summary)
+	}
+}
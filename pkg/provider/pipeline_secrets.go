@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// LiteralSecret is a value in pipeline config which looks like a secret,
+// rather than a ((var)) reference to one
+type LiteralSecret struct {
+	Kind string
+	Path string
+}
+
+var privateKeyRegex = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`)
+
+// tokenFormats are well known formats of API tokens and credentials
+var tokenFormats = []struct {
+	Kind  string
+	Regex *regexp.Regexp
+}{
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Slack webhook", regexp.MustCompile(`hooks\.slack\.com/services/[A-Za-z0-9/]+`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+}
+
+// highEntropyCandidateRegex matches strings which could be random tokens,
+// e.g. base64 or hex, as opposed to prose, paths or URLs
+var highEntropyCandidateRegex = regexp.MustCompile(`^[A-Za-z0-9+/=_-]{20,}$`)
+
+// highEntropyThreshold is in bits per character. Hex strings, such as commit
+// SHAs, cannot exceed 4.
+const highEntropyThreshold = 4.2
+
+// ScanLiteralSecrets looks for private keys, well known token formats and
+// high entropy strings in the source of resources and resource types, and
+// the params of tasks, of pipeline config which is JSON. Strings containing
+// ((var)) references are ignored, as are knownValues wherever they appear,
+// as they are the values of vars interpolated into the config.
+func ScanLiteralSecrets(configJSON string, knownValues map[string]bool) ([]LiteralSecret, error) {
+	var config map[string]interface{}
+
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, err
+	}
+
+	// longer values are masked first, in case they contain shorter ones
+	var masked []string
+	for value := range knownValues {
+		if value != "" {
+			masked = append(masked, value)
+		}
+	}
+
+	sort.Slice(masked, func(i, j int) bool {
+		return len(masked[i]) > len(masked[j])
+	})
+
+	var secrets []LiteralSecret
+
+	scan := func(root string, node interface{}) {
+		WalkJSON(node, func(path string, value interface{}) {
+			str, ok := value.(string)
+			if !ok || varReferenceRegex.MatchString(str) {
+				return
+			}
+
+			for _, value := range masked {
+				str = strings.ReplaceAll(str, value, "")
+			}
+
+			if kind := literalSecretKind(str); kind != "" {
+				if path != "" && !strings.HasPrefix(path, "[") {
+					path = "." + path
+				}
+
+				secrets = append(secrets, LiteralSecret{Kind: kind, Path: root + path})
+			}
+		})
+	}
+
+	for _, key := range []string{"resource_types", "resources"} {
+		for i, item := range policyItems(config[key]) {
+			scan(fmt.Sprintf("%s[%d].source", key, i), item["source"])
+		}
+	}
+
+	// tasks may be nested in other steps, and have params in their config
+	WalkJSON(config["jobs"], func(path string, value interface{}) {
+		step, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		if _, isTask := step["task"]; !isTask {
+			return
+		}
+
+		scan("jobs"+path+".params", step["params"])
+
+		if taskConfig, ok := step["config"].(map[string]interface{}); ok {
+			scan("jobs"+path+".config.params", taskConfig["params"])
+		}
+	})
+
+	return secrets, nil
+}
+
+func literalSecretKind(str string) string {
+	if privateKeyRegex.MatchString(str) {
+		return "private key"
+	}
+
+	for _, format := range tokenFormats {
+		if format.Regex.MatchString(str) {
+			return format.Kind
+		}
+	}
+
+	if highEntropyCandidateRegex.MatchString(str) &&
+		strings.ContainsAny(str, "0123456789") &&
+		shannonEntropy(str) > highEntropyThreshold {
+		return "high entropy string"
+	}
+
+	return ""
+}
+
+// varValues returns every string in vars, including those nested in lists
+// and maps
+func varValues(inputVars map[string]interface{}) map[string]bool {
+	values := map[string]bool{}
+
+	WalkJSON(stringKeys(inputVars), func(path string, value interface{}) {
+		if str, ok := value.(string); ok {
+			values[str] = true
+		}
+	})
+
+	return values
+}
+
+// shannonEntropy returns the entropy of str in bits per character
+func shannonEntropy(str string) float64 {
+	counts := map[rune]int{}
+	for _, r := range str {
+		counts[r]++
+	}
+
+	length := float64(len([]rune(str)))
+	entropy := 0.0
+
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// secretDetectionModes are the values of detect_secrets, which either warn
+// about or fail on literal secrets
+var secretDetectionModes = []string{
+	"warn",
+	"fail",
+}
+
+// CheckLiteralSecrets lists every literal secret in pipeline config, see
+// ScanLiteralSecrets, as an error if mode is "fail" or otherwise a warning
+func CheckLiteralSecrets(
+	configJSON string,
+	knownValues map[string]bool,
+	mode string,
+) (diag.Diagnostics, error) {
+	secrets, err := ScanLiteralSecrets(configJSON, knownValues)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString("pipeline_config appears to contain literal secrets:\n")
+
+	for _, secret := range secrets {
+		fmt.Fprintf(&msg, "  - %s at %s\n", secret.Kind, secret.Path)
+	}
+
+	msg.WriteString(
+		"use ((vars)) for them instead, or unset detect_secrets if these are not secrets",
+	)
+
+	if mode == "fail" {
+		return nil, fmt.Errorf("%s", msg.String())
+	}
+
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Pipeline config appears to contain literal secrets",
+			Detail:   msg.String(),
+		},
+	}, nil
+}
+
+// pipelineSecretWarnings scans rendered config for literal secrets when
+// detect_secrets is "warn", ignoring the values of the vars of d, which are
+// interpolated into it
+func pipelineSecretWarnings(d resourceGetter, configJSON string) diag.Diagnostics {
+	if mode, _ := d.Get("detect_secrets").(string); mode != "warn" {
+		return nil
+	}
+
+	vars, err := pipelineVars(d)
+	if err != nil {
+		return nil
+	}
+
+	warnings, _ := CheckLiteralSecrets(configJSON, varValues(vars), "warn")
+
+	return warnings
+}
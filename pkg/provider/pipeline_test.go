@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// fakePipelineClient serves a single pipeline, my-pipeline in team main,
+// with the given config
+func fakePipelineClient(config atc.Config) *concoursefakes.FakeClient {
+	team := new(concoursefakes.FakeTeam)
+	team.ListPipelinesReturns([]atc.Pipeline{{Name: "my-pipeline", TeamName: "main"}}, nil)
+	team.PipelineConfigReturns(config, "1", true, nil)
+
+	client := new(concoursefakes.FakeClient)
+	client.TeamReturns(team)
+	client.URLReturns("https://ci.example.com")
+
+	return client
+}
+
+var buildJobConfig = atc.Config{
+	Jobs: atc.JobConfigs{{
+		Name:         "build",
+		PlanSequence: []atc.Step{{Config: &atc.GetStep{Name: "repo"}}},
+	}},
+}
+
+// buildJobState is the state of my-pipeline with buildJobConfig, as read
+func buildJobState() map[string]string {
+	return map[string]string{
+		"id":                        "main:my-pipeline",
+		"team_name":                 "main",
+		"pipeline_name":             "my-pipeline",
+		"is_exposed":                "false",
+		"is_paused":                 "false",
+		"pipeline_config_format":    "yaml",
+		"pipeline_config":           "jobs:\n- name: build\n  plan: [{get: repo}]\n",
+		"conflict_strategy":         "fail",
+		"set_pipeline_parent_check": "warn",
+		"store_config_hash":         "false",
+		"url":                       "https://ci.example.com/teams/main/pipelines/my-pipeline",
+		"badge_url":                 "https://ci.example.com/api/v1/teams/main/pipelines/my-pipeline/badge",
+		"job_urls.%":                "1",
+		"job_urls.build":            "https://ci.example.com/teams/main/pipelines/my-pipeline/jobs/build",
+		"job_badge_urls.%":          "1",
+		"job_badge_urls.build":      "https://ci.example.com/api/v1/teams/main/pipelines/my-pipeline/jobs/build/badge",
+		"webhooks.%":                "0",
+	}
+}
+
+func diffPipeline(t *testing.T, state map[string]string, config map[string]interface{}) *terraform.InstanceDiff {
+	diff, err := resourcePipeline().Diff(
+		context.Background(),
+		&terraform.InstanceState{ID: "main:my-pipeline", Attributes: state},
+		terraform.NewResourceConfigRaw(config),
+		&ProviderConfig{DefaultTeam: "main", Client: fakePipelineClient(buildJobConfig)},
+	)
+
+	if err != nil {
+		t.Fatalf("error diffing pipeline: %s", err)
+	}
+
+	return diff
+}
+
+func TestPipelineConfigDiffSuppression(t *testing.T) {
+	state := buildJobState()
+
+	config := map[string]interface{}{
+		"pipeline_name":          "my-pipeline",
+		"is_exposed":             false,
+		"is_paused":              false,
+		"pipeline_config_format": "json",
+		"pipeline_config":        `{"jobs": [{"plan": [{"get": "repo"}], "name": "build"}]}`,
+	}
+
+	if diff := diffPipeline(t, state, config); diff != nil && !diff.Empty() {
+		t.Fatalf("expected equivalent config to have no diff, got %v", diff.Attributes)
+	}
+
+	config["pipeline_config"] = `{"jobs": [{"plan": [{"get": "other-repo"}], "name": "build"}]}`
+
+	diff := diffPipeline(t, state, config)
+	if diff == nil || diff.Attributes["pipeline_config"] == nil {
+		t.Fatalf("expected changed config to have a diff")
+	}
+}
+
+func TestPipelineConfigDiff(t *testing.T) {
+	state := buildJobState()
+
+	config := map[string]interface{}{
+		"pipeline_name":          "my-pipeline",
+		"is_exposed":             false,
+		"is_paused":              false,
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        "jobs:\n- name: build\n  plan: [{get: other-repo}]\n- name: test\n  plan: [{get: repo}]\n",
+	}
+
+	diff := diffPipeline(t, state, config)
+	if diff == nil || diff.Attributes["config_diff"] == nil {
+		t.Fatalf("expected a config_diff")
+	}
+
+	configDiff := diff.Attributes["config_diff"].New
+	for _, expected := range []string{
+		"jobs:\n",
+		"  job build has changed:\n",
+		"  - - get: repo\n",
+		"  + - get: other-repo\n",
+		"  job test has been added:\n",
+	} {
+		if !strings.Contains(configDiff, expected) {
+			t.Fatalf("expected config_diff to contain %q, got:\n%s", expected, configDiff)
+		}
+	}
+
+	jobURL := diff.Attributes["job_urls.test"]
+	if jobURL == nil || jobURL.New != "https://ci.example.com/teams/main/pipelines/my-pipeline/jobs/test" {
+		t.Fatalf("expected a job_url for the new job, got %v", jobURL)
+	}
+}
+
+// unknownValue is how terraform represents values which are not known until
+// apply in raw config
+const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestPipelineConfigUnknown(t *testing.T) {
+	state := buildJobState()
+	state["store_config_hash"] = "true"
+	state["config_hash"] = "abc"
+
+	config := map[string]interface{}{
+		"pipeline_name":          "my-pipeline",
+		"is_exposed":             false,
+		"is_paused":              false,
+		"store_config_hash":      true,
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        unknownValue,
+	}
+
+	diff := diffPipeline(t, state, config)
+	if diff == nil {
+		t.Fatalf("expected a diff")
+	}
+
+	for _, key := range []string{"config_diff", "var_files_hash", "job_urls.%", "job_badge_urls.%", "webhooks.%", "config_hash"} {
+		if attr := diff.Attributes[key]; attr == nil || !attr.NewComputed {
+			t.Fatalf("expected %s to be unknown, got %v", key, attr)
+		}
+	}
+}
+
+func TestPipelineWebhooks(t *testing.T) {
+	configJSON := `{"resources": [
+		{"name": "repo", "type": "git", "webhook_token": "s3cret&more"},
+		{"name": "vault repo", "type": "git", "webhook_token": "((webhook_token))"},
+		{"name": "time", "type": "time"},
+		{"name": "other repo", "type": "git", "webhook_token": "abc"}
+	]}`
+
+	webhooks, err := PipelineWebhooks("https://ci.example.com/", "my team", "my-pipeline", configJSON)
+	if err != nil {
+		t.Fatalf("error getting webhooks: %s", err)
+	}
+
+	expected := map[string]string{
+		"repo":       "https://ci.example.com/api/v1/teams/my%20team/pipelines/my-pipeline/resources/repo/check/webhook?webhook_token=s3cret%26more",
+		"other repo": "https://ci.example.com/api/v1/teams/my%20team/pipelines/my-pipeline/resources/other%20repo/check/webhook?webhook_token=abc",
+	}
+
+	if !reflect.DeepEqual(webhooks, expected) {
+		t.Fatalf("\n\nexpected:\n\n%v\n\ngot:\n\n%v\n\n", expected, webhooks)
+	}
+}
+
+func TestPipelineConfigDiffFromState(t *testing.T) {
+	configJSON, err := json.Marshal(buildJobConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := buildJobState()
+	state["json"], _ = JSONToJSON(string(configJSON))
+	state["config_diff"] = "jobs:\n  job build has changed:\n"
+
+	config := map[string]interface{}{
+		"pipeline_name":          "my-pipeline",
+		"is_exposed":             false,
+		"is_paused":              false,
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        state["pipeline_config"],
+	}
+
+	client := fakePipelineClient(atc.Config{})
+	diffWithClient := func() *terraform.InstanceDiff {
+		diff, err := resourcePipeline().Diff(
+			context.Background(),
+			&terraform.InstanceState{ID: "main:my-pipeline", Attributes: state},
+			terraform.NewResourceConfigRaw(config),
+			&ProviderConfig{DefaultTeam: "main", Client: client},
+		)
+		if err != nil {
+			t.Fatalf("error diffing pipeline: %s", err)
+		}
+		return diff
+	}
+
+	// a stale diff is cleared, without looking up the pipeline's config
+	diff := diffWithClient()
+	if diff == nil || diff.Attributes["config_diff"] == nil || diff.Attributes["config_diff"].New != "" {
+		t.Fatalf("expected the stale config_diff to be cleared, got %v", diff)
+	}
+
+	if client.TeamCallCount() != 0 {
+		t.Fatalf("expected the refreshed config in state to be used")
+	}
+
+	// vars may be secrets, so only the names of what changed are shown
+	state["config_diff"] = ""
+	config["pipeline_config"] = "jobs:\n- name: build\n  plan: [{get: ((repo))}]\n"
+	config["vars"] = map[string]interface{}{"repo": "other-repo"}
+
+	diff = diffWithClient()
+	if diff == nil || diff.Attributes["config_diff"] == nil {
+		t.Fatalf("expected a config_diff")
+	}
+
+	if configDiff := diff.Attributes["config_diff"].New; configDiff != "  - job build has changed\n" {
+		t.Fatalf("expected only a summary of the changes, got:\n%s", configDiff)
+	}
+}
+
+func TestSetPipelineConfigSkipsUnchangedConfig(t *testing.T) {
+	client := fakePipelineClient(buildJobConfig)
+
+	// serial: false is a default, which concourse leaves out
+	d := schema.TestResourceDataRaw(t, resourcePipeline().Schema, map[string]interface{}{
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        "jobs:\n- name: build\n  serial: false\n  plan: [{get: repo}]\n",
+	})
+
+	diags := setPipelineConfig(context.Background(), d, &ProviderConfig{Client: client}, "main", "my-pipeline")
+	if diags.HasError() {
+		t.Fatalf("error setting pipeline config: %v", diags)
+	}
+
+	if count := client.Team("main").(*concoursefakes.FakeTeam).CreateOrUpdatePipelineConfigCallCount(); count != 0 {
+		t.Fatalf("expected unchanged config not to be set, got %d calls", count)
+	}
+}
+
+// conflictingPipeline is my-pipeline, configured to get other-repo rather
+// than repo, with a client whose every attempt to set its config conflicts
+func conflictingPipeline(t *testing.T, conflictStrategy string) (*schema.ResourceData, *concoursefakes.FakeTeam, *ProviderConfig) {
+	// the pipeline was not set by a set_pipeline step
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	client := fakePipelineClient(buildJobConfig)
+	client.URLReturns(server.URL)
+	client.HTTPClientReturns(http.DefaultClient)
+
+	team := client.Team("main").(*concoursefakes.FakeTeam)
+	team.CreateOrUpdatePipelineConfigReturns(false, false, nil, errors.New(
+		"forbidden: comparison with existing config failed during save",
+	))
+
+	d := schema.TestResourceDataRaw(t, resourcePipeline().Schema, map[string]interface{}{
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        "jobs:\n- name: build\n  plan: [{get: other-repo}]\n",
+		"conflict_strategy":      conflictStrategy,
+	})
+
+	return d, team, &ProviderConfig{Client: client}
+}
+
+func TestSetPipelineConfigConflictFails(t *testing.T) {
+	d, team, providerConfig := conflictingPipeline(t, "fail")
+
+	diags := setPipelineConfig(context.Background(), d, providerConfig, "main", "my-pipeline")
+	if !diags.HasError() {
+		t.Fatalf("expected a conflict error")
+	}
+
+	if !strings.HasPrefix(diags[0].Summary, "Conflict setting config for pipeline my-pipeline") ||
+		!strings.Contains(diags[0].Detail, "was changed by something else after terraform read config version 1") ||
+		!strings.Contains(diags[0].Detail, "probably set with fly set-pipeline") {
+		t.Fatalf("expected the conflict to be explained, got: %v", diags)
+	}
+
+	if count := team.CreateOrUpdatePipelineConfigCallCount(); count != 1 {
+		t.Fatalf("expected config to be set once, got %d calls", count)
+	}
+}
+
+func TestSetPipelineConfigConflictRetries(t *testing.T) {
+	d, team, providerConfig := conflictingPipeline(t, "retry")
+
+	diags := setPipelineConfig(context.Background(), d, providerConfig, "main", "my-pipeline")
+	if !diags.HasError() {
+		t.Fatalf("expected a conflict error once retries ran out")
+	}
+
+	if count := team.CreateOrUpdatePipelineConfigCallCount(); count != 1+pipelineConfigConflictRetries {
+		t.Fatalf("expected %d attempts, got %d", 1+pipelineConfigConflictRetries, count)
+	}
+
+	if count := team.PipelineConfigCallCount(); count != 1+pipelineConfigConflictRetries {
+		t.Fatalf("expected config to be re-read before each retry, got %d reads", count)
+	}
+}
+
+func TestSetPipelineConfigConflictWithSameConfig(t *testing.T) {
+	d, team, providerConfig := conflictingPipeline(t, "retry")
+
+	// whatever changed the pipeline set it to the config being applied
+	team.PipelineConfigReturnsOnCall(1, atc.Config{
+		Jobs: atc.JobConfigs{{
+			Name:         "build",
+			PlanSequence: []atc.Step{{Config: &atc.GetStep{Name: "other-repo"}}},
+		}},
+	}, "2", true, nil)
+
+	diags := setPipelineConfig(context.Background(), d, providerConfig, "main", "my-pipeline")
+	if diags.HasError() {
+		t.Fatalf("expected no error, got: %v", diags)
+	}
+
+	if count := team.CreateOrUpdatePipelineConfigCallCount(); count != 1 {
+		t.Fatalf("expected the identical config not to be sent again, got %d calls", count)
+	}
+}
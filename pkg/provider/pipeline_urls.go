@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/alphagov/terraform-provider-concourse/pkg/client"
+)
+
+// pipelineLinkKeys are the attributes set by PipelineLinks, with string or
+// map values
+var pipelineLinkKeys = []string{
+	"url",
+	"badge_url",
+	"job_urls",
+	"job_badge_urls",
+	"webhooks",
+}
+
+// webPath joins escaped path segments, as APIPath does for the web UI
+func webPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	return "/" + strings.Join(escaped, "/")
+}
+
+// PipelineLinks returns the web UI and badge URLs of a pipeline and each of
+// its jobs, and its webhook URLs (see PipelineWebhooks), keyed by
+// pipelineLinkKeys. Instanced pipelines, which would need their instance
+// vars in a "vars" query parameter, are not supported by the vendored
+// go-concourse, so are not handled here.
+func PipelineLinks(
+	atcURL string,
+	teamName string,
+	pipelineName string,
+	configJSON string,
+) (map[string]interface{}, error) {
+	atcURL = strings.TrimSuffix(atcURL, "/")
+
+	jobURLs := map[string]string{}
+	jobBadgeURLs := map[string]string{}
+
+	if configJSON != "" {
+		var config map[string]interface{}
+		if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+			return nil, err
+		}
+
+		for _, job := range policyItems(config["jobs"]) {
+			name, _ := job["name"].(string)
+
+			jobURLs[name] = atcURL +
+				webPath("teams", teamName, "pipelines", pipelineName, "jobs", name)
+			jobBadgeURLs[name] = atcURL +
+				client.APIPath("teams", teamName, "pipelines", pipelineName, "jobs", name, "badge")
+		}
+	}
+
+	webhooks, err := PipelineWebhooks(atcURL, teamName, pipelineName, configJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"url":            atcURL + webPath("teams", teamName, "pipelines", pipelineName),
+		"badge_url":      atcURL + client.APIPath("teams", teamName, "pipelines", pipelineName, "badge"),
+		"job_urls":       jobURLs,
+		"job_badge_urls": jobBadgeURLs,
+		"webhooks":       webhooks,
+	}, nil
+}
+
+// PipelineWebhooks returns the webhook URL of every resource in pipeline
+// config which has a webhook_token, by resource name. Tokens which are still
+// ((var)) references, e.g. to a credential manager, are left out, as the URL
+// cannot be known.
+func PipelineWebhooks(
+	atcURL string,
+	teamName string,
+	pipelineName string,
+	configJSON string,
+) (map[string]string, error) {
+	webhooks := map[string]string{}
+
+	if configJSON == "" {
+		return webhooks, nil
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, err
+	}
+
+	for _, resource := range policyItems(config["resources"]) {
+		name, _ := resource["name"].(string)
+		token, _ := resource["webhook_token"].(string)
+
+		if token == "" || varReferenceRegex.MatchString(token) {
+			continue
+		}
+
+		webhooks[name] = strings.TrimSuffix(atcURL, "/") +
+			client.APIPath("teams", teamName, "pipelines", pipelineName, "resources", name, "check", "webhook") +
+			"?webhook_token=" + url.QueryEscape(token)
+	}
+
+	return webhooks, nil
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func pipelineVarFiles(d resourceGetter) []string {
+	var paths []string
+	for _, path := range d.Get("var_files").([]interface{}) {
+		paths = append(paths, path.(string))
+	}
+	return paths
+}
+
+// hashVarFiles returns a SHA-256 hash of the contents of var files, so that
+// changes to them are noticed even though only their paths are configured
+func hashVarFiles(paths []string) (string, error) {
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	hash := sha256.New()
+
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("Error reading var file: %s", err)
+		}
+
+		fmt.Fprintf(hash, "%s\x00%d\x00", path, len(contents))
+		hash.Write(contents)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// varReferenceRegex matches ((var)) references, as concourse does
+var varReferenceRegex = regexp.MustCompile(`\(\((([-/\.\w\pL]+\:)?[-/\.:"\w\pL]+)\)\)`)
+
+// UnresolvedVar is a ((var)) reference left in a pipeline's config after
+// interpolating the vars supplied to terraform
+type UnresolvedVar struct {
+	Name string
+	Path string
+}
+
+// UnresolvedVars finds every ((var)) reference in parsed pipeline config,
+// except references to the pipeline's own var_sources and those matching
+// allowed. An allowed entry matches a var name exactly, or by prefix if it
+// ends with "*".
+func UnresolvedVars(parsedJSON string, allowed []string) ([]UnresolvedVar, error) {
+	var config interface{}
+
+	if err := json.Unmarshal([]byte(parsedJSON), &config); err != nil {
+		return nil, err
+	}
+
+	varSources := map[string]bool{}
+
+	if root, ok := config.(map[string]interface{}); ok {
+		if sources, ok := root["var_sources"].([]interface{}); ok {
+			for _, source := range sources {
+				if source, ok := source.(map[string]interface{}); ok {
+					if name, ok := source["name"].(string); ok {
+						varSources[name] = true
+					}
+				}
+			}
+		}
+	}
+
+	var unresolved []UnresolvedVar
+
+	WalkJSON(config, func(path string, value interface{}) {
+		str, ok := value.(string)
+		if !ok {
+			return
+		}
+
+		for _, match := range varReferenceRegex.FindAllStringSubmatch(str, -1) {
+			name := match[1]
+
+			if source := strings.TrimSuffix(match[2], ":"); varSources[source] {
+				continue
+			}
+
+			if nameAllowed(name, allowed) {
+				continue
+			}
+
+			unresolved = append(unresolved, UnresolvedVar{Name: name, Path: path})
+		}
+	})
+
+	return unresolved, nil
+}
+
+// CheckUnresolvedVars returns an error listing every unresolved var in
+// parsed pipeline config, see UnresolvedVars
+func CheckUnresolvedVars(parsedJSON string, allowed []string) error {
+	unresolved, err := UnresolvedVars(parsedJSON, allowed)
+	if err != nil {
+		return err
+	}
+
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString("pipeline_config has unresolved vars:\n")
+
+	for _, v := range unresolved {
+		fmt.Fprintf(&msg, "  - ((%s)) at %s\n", v.Name, v.Path)
+	}
+
+	msg.WriteString(
+		"supply them in vars, or add them to allowed_unresolved if they are for a credential manager",
+	)
+
+	return fmt.Errorf("%s", msg.String())
+}
+
+// WalkJSON calls fn for every value in unmarshalled JSON, in a stable order,
+// with the path to that value, e.g. jobs[0].plan[1].get
+func WalkJSON(node interface{}, fn func(path string, value interface{})) {
+	walkJSON(node, "", fn)
+}
+
+func walkJSON(node interface{}, path string, fn func(string, interface{})) {
+	fn(path, node)
+
+	switch typedNode := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typedNode))
+		for key := range typedNode {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			walkJSON(typedNode[key], childPath, fn)
+		}
+
+	case []interface{}:
+		for i, child := range typedNode {
+			walkJSON(child, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUnresolvedVars(t *testing.T) {
+	parsedJSON := `{
+		"var_sources": [{"name": "vault", "type": "vault", "config": {}}],
+		"resources": [{
+			"name": "repo",
+			"type": "git",
+			"source": {
+				"uri": "((git-uri))",
+				"private_key": "((github-deploy-key))",
+				"branch": "((vault:branch))",
+				"tag_filter": "((prefix))-((suffix))"
+			}
+		}]
+	}`
+
+	expected := []UnresolvedVar{
+		{Name: "prefix", Path: "resources[0].source.tag_filter"},
+		{Name: "suffix", Path: "resources[0].source.tag_filter"},
+		{Name: "git-uri", Path: "resources[0].source.uri"},
+	}
+
+	actual, err := UnresolvedVars(parsedJSON, []string{"github-*"})
+
+	if err != nil {
+		t.Fatalf("error finding unresolved vars: %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("\n\nexpected:\n\n%#v\n\ngot:\n\n%#v\n\n", expected, actual)
+	}
+}
+
+func TestStrictVarsDefaultsToProvider(t *testing.T) {
+	providerConfig := &ProviderConfig{StrictVars: true}
+
+	for strictVars, expectError := range map[string]bool{"": true, "true": true, "false": false} {
+		d := schema.TestResourceDataRaw(t, resourcePipeline().Schema, map[string]interface{}{
+			"pipeline_config":        `{"resources": [{"name": "repo", "type": "git", "source": {"uri": "((git-uri))"}}]}`,
+			"pipeline_config_format": "json",
+			"strict_vars":            strictVars,
+		})
+
+		_, err := renderPipelineConfig(d, providerConfig)
+		if (err != nil) != expectError {
+			t.Fatalf("strict_vars %q: expected error %t, got %v", strictVars, expectError, err)
+		}
+	}
+}
+
+func TestVarFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yml")
+	second := filepath.Join(dir, "second.yml")
+
+	if err := ioutil.WriteFile(first, []byte("branch: main\nregion: eu-west-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(second, []byte("branch: release\nreplicas: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePipeline().Schema, map[string]interface{}{
+		"var_files": []interface{}{first, second},
+		"vars":      map[string]interface{}{"region": "eu-west-2"},
+	})
+
+	vars, err := pipelineVars(d)
+	if err != nil {
+		t.Fatalf("error merging vars: %s", err)
+	}
+
+	expected := map[string]interface{}{"branch": "release", "region": "eu-west-2", "replicas": 2}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("\n\nexpected:\n\n%#v\n\ngot:\n\n%#v\n\n", expected, vars)
+	}
+
+	hash, err := hashVarFiles([]string{first, second})
+	if err != nil {
+		t.Fatalf("error hashing var files: %s", err)
+	}
+
+	if err := ioutil.WriteFile(second, []byte("branch: release\nreplicas: 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, _ := hashVarFiles([]string{first, second}); changed == hash {
+		t.Fatalf("expected the hash to change with the contents of a var file")
+	}
+
+	// a plan notices changes to the contents of var files
+	state := buildJobState()
+	state["var_files.#"] = "2"
+	state["var_files.0"] = first
+	state["var_files.1"] = second
+	state["var_files_hash"] = hash
+
+	diff := diffPipeline(t, state, map[string]interface{}{
+		"pipeline_name":          "my-pipeline",
+		"is_exposed":             false,
+		"is_paused":              false,
+		"pipeline_config_format": "yaml",
+		"pipeline_config":        state["pipeline_config"],
+		"var_files":              []interface{}{first, second},
+	})
+
+	if diff == nil || diff.Attributes["var_files_hash"] == nil {
+		t.Fatalf("expected a diff of var_files_hash")
+	}
+
+	if _, err := hashVarFiles([]string{filepath.Join(dir, "missing.yml")}); err == nil {
+		t.Fatalf("expected an error hashing a missing var file")
+	}
+
+	missing := schema.TestResourceDataRaw(t, resourcePipeline().Schema, map[string]interface{}{
+		"var_files": []interface{}{filepath.Join(dir, "missing.yml")},
+	})
+
+	if _, err := pipelineVars(missing); err == nil {
+		t.Fatalf("expected an error reading a missing var file")
+	}
+}
@@ -37,19 +37,45 @@ func Provider() *schema.Provider {
 				Description: "Password, do not use if using target ",
 				Optional:    true,
 			},
+			"default_team": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("FLY_DEFAULT_TEAM", nil),
+				Description: "Team used when a resource omits team_name, defaults to the team being logged in to",
+				Optional:    true,
+			},
+			"strict_vars": {
+				Type:        schema.TypeBool,
+				Description: "Default for strict_vars on concourse_pipeline resources",
+				Optional:    true,
+			},
+			"pipeline_policy": pipelinePolicySchema(),
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				DefaultFunc: schema.EnvDefaultFunc("FLY_SKIP_CREDENTIALS_VALIDATION", false),
+				Description: "Do not require target or credentials until a request is made to concourse",
+				Optional:    true,
+			},
 		},
 
 		ConfigureFunc: ProviderConfigurationBuilder,
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"concourse_pipeline": dataPipeline(),
-			"concourse_team":     dataTeam(),
-			"concourse_teams":    dataTeams(),
+			"concourse_pipeline":            dataPipeline(),
+			"concourse_pipeline_definition": dataPipelineDefinition(),
+			"concourse_pipeline_config":     dataPipelineConfig(),
+			"concourse_team":                dataTeam(),
+			"concourse_teams":               dataTeams(),
+			"concourse_wall":                dataWall(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"concourse_pipeline": resourcePipeline(),
-			"concourse_team":     resourceTeam(),
+			"concourse_pipeline":         resourcePipeline(),
+			"concourse_pipeline_order":   resourcePipelineOrder(),
+			"concourse_resource_check":   resourceResourceCheck(),
+			"concourse_team":             resourceTeam(),
+			"concourse_team_pipelines":   resourceTeamPipelines(),
+			"concourse_wall":             resourceWall(),
+			"concourse_worker_lifecycle": resourceWorkerLifecycle(),
 		},
 	}
 }
@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// checkPollInterval is how often a check is polled while waiting for it
+var checkPollInterval = time.Second
+
+func resourceResourceCheck() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceResourceCheckCreate,
+		ReadContext:   resourceResourceCheckRead,
+		DeleteContext: resourceResourceCheckDelete,
+
+		CustomizeDiff: resourceResourceCheckCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"team_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"pipeline_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"resource_name": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"resource_name", "resource_type_name"},
+			},
+
+			"resource_type_name": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"resource_name", "resource_type_name"},
+			},
+
+			"from_version": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"wait": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				ForceNew: true,
+			},
+
+			"triggers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"check_id": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceResourceCheckCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	providerConfig := m.(*ProviderConfig)
+
+	if _, ok := d.GetOk("team_name"); !ok {
+		teamName, err := providerConfig.ResolveTeamName("")
+		if err != nil {
+			return err
+		}
+
+		return d.SetNew("team_name", teamName)
+	}
+
+	return nil
+}
+
+func resourceResourceCheckCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Get("team_name").(string)
+	pipelineName := d.Get("pipeline_name").(string)
+	team := providerConfig.Client.Team(teamName)
+
+	version := atc.Version{}
+	for key, value := range d.Get("from_version").(map[string]interface{}) {
+		version[key] = value.(string)
+	}
+
+	var check atc.Check
+	var found bool
+	var err error
+	var description string
+
+	if resourceName, ok := d.GetOk("resource_name"); ok {
+		description = fmt.Sprintf("resource %s", resourceName)
+		check, found, err = team.CheckResource(pipelineName, resourceName.(string), version)
+	} else {
+		resourceTypeName := d.Get("resource_type_name").(string)
+		description = fmt.Sprintf("resource type %s", resourceTypeName)
+		check, found, err = team.CheckResourceType(pipelineName, resourceTypeName, version)
+	}
+
+	if err != nil {
+		return diag.Errorf(
+			"Error checking %s in pipeline %s of team '%s': %s",
+			description, pipelineName, teamName, err,
+		)
+	}
+
+	if !found {
+		return diag.Errorf(
+			"Could not find %s in pipeline %s of team '%s'",
+			description, pipelineName, teamName,
+		)
+	}
+
+	if d.Get("wait").(bool) {
+		check, err = waitForCheck(ctx, providerConfig, check, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.Errorf(
+				"Error waiting for check of %s in pipeline %s of team '%s': %s",
+				description, pipelineName, teamName, err,
+			)
+		}
+
+		if check.Status == "errored" {
+			return diag.Errorf(
+				"Check of %s in pipeline %s of team '%s' failed: %s",
+				description, pipelineName, teamName, check.CheckError,
+			)
+		}
+	}
+
+	d.SetId(strconv.Itoa(check.ID))
+	d.Set("check_id", check.ID)
+	d.Set("status", check.Status)
+
+	return nil
+}
+
+// waitForCheck polls a check until it has succeeded or errored
+func waitForCheck(
+	ctx context.Context,
+	providerConfig *ProviderConfig,
+	check atc.Check,
+	timeout time.Duration,
+) (atc.Check, error) {
+	deadline := time.Now().Add(timeout)
+
+	for check.Status != "succeeded" && check.Status != "errored" {
+		if time.Now().After(deadline) {
+			return check, fmt.Errorf("check %d is still %s after %s", check.ID, check.Status, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return check, ctx.Err()
+		case <-time.After(checkPollInterval):
+		}
+
+		latest, found, err := providerConfig.Client.Check(strconv.Itoa(check.ID))
+		if err != nil {
+			return check, err
+		}
+
+		if !found {
+			return check, fmt.Errorf("check %d no longer exists", check.ID)
+		}
+
+		check = latest
+	}
+
+	return check, nil
+}
+
+func resourceResourceCheckRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// a check is a one off action, which is only repeated when its arguments
+	// or triggers change
+	return nil
+}
+
+func resourceResourceCheckDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceCheckWaitsForCheck(t *testing.T) {
+	pollInterval := checkPollInterval
+	t.Cleanup(func() { checkPollInterval = pollInterval })
+	checkPollInterval = 0
+
+	team := new(concoursefakes.FakeTeam)
+	team.CheckResourceReturns(atc.Check{ID: 12, Status: "started"}, true, nil)
+
+	client := new(concoursefakes.FakeClient)
+	client.TeamReturns(team)
+	client.CheckReturnsOnCall(0, atc.Check{ID: 12, Status: "started"}, true, nil)
+	client.CheckReturnsOnCall(1, atc.Check{ID: 12, Status: "errored", CheckError: "repository not found"}, true, nil)
+
+	d := schema.TestResourceDataRaw(t, resourceResourceCheck().Schema, map[string]interface{}{
+		"team_name":     "main",
+		"pipeline_name": "my-pipeline",
+		"resource_name": "repo",
+		"from_version":  map[string]interface{}{"ref": "abc123"},
+	})
+
+	diags := resourceResourceCheckCreate(context.Background(), d, &ProviderConfig{Client: client})
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "repository not found") {
+		t.Fatalf("expected the check's error, got %v", diags)
+	}
+
+	pipelineName, resourceName, version := team.CheckResourceArgsForCall(0)
+	if pipelineName != "my-pipeline" || resourceName != "repo" || version["ref"] != "abc123" {
+		t.Fatalf("unexpected check of %s/%s from %v", pipelineName, resourceName, version)
+	}
+
+	if client.CheckCallCount() != 2 || d.Id() != "" {
+		t.Fatalf("expected to poll the check until it errored, without creating the resource")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/concourse/concourse/atc"
-	"github.com/concourse/concourse/go-concourse/concourse"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -171,11 +170,11 @@ func (t *teamHelper) appendElem(field string, elem string) {
 
 func readTeam(
 	ctx context.Context,
-	client concourse.Client,
+	providerConfig *ProviderConfig,
 	teamName string,
 ) (teamHelper, diag.Diagnostics) {
 
-	team, err := client.FindTeam(teamName)
+	team, found, err := providerConfig.FindTeam(teamName)
 
 	retVal := teamHelper{
 		TeamName: teamName,
@@ -185,7 +184,7 @@ func readTeam(
 		return retVal, diag.FromErr(err)
 	}
 
-	if team == nil {
+	if !found {
 		return retVal, diag.Errorf("Could not find team %s", teamName)
 	}
 
@@ -195,7 +194,7 @@ func readTeam(
 	)
 
 	for _, roleName := range roleNames {
-		if role, ok = team.Auth()[roleName]; !ok {
+		if role, ok = team.Auth[roleName]; !ok {
 			continue
 		}
 
@@ -219,10 +218,10 @@ func readTeam(
 }
 
 func dataTeamRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
 	teamName := d.Get("team_name").(string)
 
-	team, err := readTeam(ctx, client, teamName)
+	team, err := readTeam(ctx, providerConfig, teamName)
 
 	if err != nil {
 		return err
@@ -246,8 +245,8 @@ func resourceTeamUpdate(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 func resourceTeamRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
-	team, err := readTeam(ctx, client, d.Id())
+	providerConfig := m.(*ProviderConfig)
+	team, err := readTeam(ctx, providerConfig, d.Id())
 
 	if err != nil {
 		return err
@@ -263,7 +262,8 @@ func resourceTeamRead(ctx context.Context, d *schema.ResourceData, m interface{}
 }
 
 func resourceTeamCreateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}, create bool) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
+	client := providerConfig.Client
 	teamName := d.Get("team_name").(string)
 	auths := make(map[string][]string)
 
@@ -311,6 +311,7 @@ func resourceTeamCreateUpdate(ctx context.Context, d *schema.ResourceData, m int
 
 	if d.HasChange("team_name") && !create {
 		_, warnings, err := team.RenameTeam(d.Id(), d.Get("team_name").(string))
+		providerConfig.InvalidateTeams()
 
 		if err != nil {
 			return diag.Errorf("Could not rename team %s %s", teamName, SerializeWarnings(warnings))
@@ -318,6 +319,7 @@ func resourceTeamCreateUpdate(ctx context.Context, d *schema.ResourceData, m int
 	}
 
 	_, created, updated, warnings, err := team.CreateOrUpdate(teamDetails)
+	providerConfig.InvalidateTeams()
 
 	if err != nil {
 		return diag.Errorf("Error creating/updating team %s: %s %s", teamName, err, SerializeWarnings(warnings))
@@ -332,7 +334,8 @@ func resourceTeamCreateUpdate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceTeamDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
+	providerConfig := m.(*ProviderConfig)
+	client := providerConfig.Client
 	teamName := d.Get("team_name").(string)
 
 	if teamName == "main" {
@@ -342,6 +345,8 @@ func resourceTeamDelete(ctx context.Context, d *schema.ResourceData, m interface
 	team := client.Team(teamName)
 
 	err := team.DestroyTeam(teamName)
+	providerConfig.InvalidateTeams()
+	providerConfig.InvalidatePipelines(teamName)
 
 	if err != nil {
 		return diag.Errorf("Could not delete team %s: %s", teamName, err)
@@ -0,0 +1,506 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var pruneStrategies = []string{"archive", "delete"}
+
+func resourceTeamPipelines() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceTeamPipelinesCreateUpdate,
+		ReadContext:   resourceTeamPipelinesRead,
+		UpdateContext: resourceTeamPipelinesCreateUpdate,
+		DeleteContext: resourceTeamPipelinesDelete,
+
+		CustomizeDiff: resourceTeamPipelinesCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"team_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"pipeline": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     teamPipelineBlock(),
+			},
+
+			"prune_strategy": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "archive",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(pruneStrategies, false)),
+			},
+
+			"exclude": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNamePattern,
+				},
+			},
+
+			"config_hashes": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"paused_pipelines": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeBool,
+				},
+			},
+
+			"exposed_pipelines": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeBool,
+				},
+			},
+
+			"unmanaged_pipelines": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// teamPipelineBlock has the same arguments as concourse_pipeline, except for
+// team_name, strict_vars, which comes from the provider, and store_config_hash,
+// as only hashes of config are stored
+func teamPipelineBlock() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"pipeline_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"is_exposed": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"is_paused": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"pipeline_config_format": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"json", "yaml", "jsonnet"}, false)),
+			},
+
+			"jsonnet_library_paths": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"pipeline_config": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"vars": &schema.Schema{
+				Type:      schema.TypeMap,
+				Optional:  true,
+				Sensitive: true,
+			},
+
+			"var_files": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"vars_yaml": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validateVars,
+			},
+
+			"vars_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validateVars,
+			},
+
+			"allowed_unresolved": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateNamePattern,
+				},
+			},
+
+			"detect_secrets": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(secretDetectionModes, false)),
+			},
+
+			"conflict_strategy": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "fail",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(conflictStrategies, false)),
+			},
+		},
+	}
+}
+
+// teamPipeline is a pipeline block, which provides the same arguments to
+// renderPipelineConfig and friends as a concourse_pipeline resource
+type teamPipeline map[string]interface{}
+
+func (p teamPipeline) Get(key string) interface{} {
+	return p[key]
+}
+
+func teamPipelines(d resourceGetter) ([]teamPipeline, error) {
+	var pipelines []teamPipeline
+	seen := map[string]bool{}
+
+	for _, block := range d.Get("pipeline").([]interface{}) {
+		pipeline := teamPipeline(block.(map[string]interface{}))
+		name := pipeline.Get("pipeline_name").(string)
+
+		if seen[name] {
+			return nil, fmt.Errorf("pipeline %q is defined more than once", name)
+		}
+
+		seen[name] = true
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
+
+// isUnmanagedPipeline is whether a pipeline in the team should be pruned
+func isUnmanagedPipeline(d resourceGetter, managed map[string]bool, name string) bool {
+	if managed[name] {
+		return false
+	}
+
+	var exclude []string
+	for _, pattern := range d.Get("exclude").([]interface{}) {
+		exclude = append(exclude, pattern.(string))
+	}
+
+	return !nameAllowed(name, exclude)
+}
+
+func resourceTeamPipelinesCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	providerConfig := m.(*ProviderConfig)
+
+	if _, ok := d.GetOk("team_name"); !ok {
+		teamName, err := providerConfig.ResolveTeamName("")
+		if err != nil {
+			return err
+		}
+
+		if err := d.SetNew("team_name", teamName); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Get("unmanaged_pipelines").([]interface{})) > 0 {
+		if err := d.SetNew("unmanaged_pipelines", []string{}); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("pipeline") {
+		return setTeamPipelinesUnknown(d)
+	}
+
+	pipelines, err := teamPipelines(d)
+	if err != nil {
+		return err
+	}
+
+	// render every pipeline now, so that mistakes fail the plan not the apply
+	configHashes := map[string]interface{}{}
+	paused := map[string]interface{}{}
+	exposed := map[string]interface{}{}
+
+	blockKeys := append([]string{"is_paused", "is_exposed"}, pipelineConfigKeys...)
+
+	for i, pipeline := range pipelines {
+		for _, key := range blockKeys {
+			if !d.NewValueKnown(fmt.Sprintf("pipeline.%d.%s", i, key)) {
+				return setTeamPipelinesUnknown(d)
+			}
+		}
+
+		name := pipeline.Get("pipeline_name").(string)
+		paused[name] = pipeline.Get("is_paused").(bool)
+		exposed[name] = pipeline.Get("is_exposed").(bool)
+
+		parsedJSON, err := renderPipelineConfig(pipeline, providerConfig)
+		if err != nil {
+			return fmt.Errorf("pipeline %s: %s", name, err)
+		}
+
+		if d.NewValueKnown("team_name") {
+			_, err := CheckPipelinePolicy(
+				providerConfig.PipelinePolicy, d.Get("team_name").(string), name, parsedJSON,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		configHashes[name], err = HashPipelineConfig(parsedJSON)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the pipelines were changed, paused, unpaused, exposed or hidden, or are
+	// missing, or there are new ones
+	for key, value := range map[string]map[string]interface{}{
+		"config_hashes":     configHashes,
+		"paused_pipelines":  paused,
+		"exposed_pipelines": exposed,
+	} {
+		if reflect.DeepEqual(value, d.Get(key).(map[string]interface{})) {
+			continue
+		}
+
+		if err := d.SetNew(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setTeamPipelinesUnknown marks what is worked out from the pipeline blocks
+// as unknown, when they are not known until apply
+func setTeamPipelinesUnknown(d *schema.ResourceDiff) error {
+	for _, key := range []string{"config_hashes", "paused_pipelines", "exposed_pipelines"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceTeamPipelinesCreateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Get("team_name").(string)
+	team := providerConfig.Client.Team(teamName)
+
+	pipelines, err := teamPipelines(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(teamName)
+
+	var diags diag.Diagnostics
+	managed := map[string]bool{}
+
+	for _, pipeline := range pipelines {
+		pipelineName := pipeline.Get("pipeline_name").(string)
+		managed[pipelineName] = true
+
+		diags = append(diags, setPipelineConfig(ctx, pipeline, providerConfig, teamName, pipelineName)...)
+		if diags.HasError() {
+			return diags
+		}
+
+		current, found, err := providerConfig.FindPipeline(teamName, pipelineName)
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+
+		if !found || current.Public != pipeline.Get("is_exposed").(bool) {
+			if setDiags := setPipelineExposed(pipeline, providerConfig, teamName, pipelineName); setDiags != nil {
+				return append(diags, setDiags...)
+			}
+		}
+
+		if !found || current.Paused != pipeline.Get("is_paused").(bool) {
+			if setDiags := setPipelinePaused(pipeline, providerConfig, teamName, pipelineName); setDiags != nil {
+				return append(diags, setDiags...)
+			}
+		}
+	}
+
+	existing, err := providerConfig.ListPipelines(teamName)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	pruneStrategy := d.Get("prune_strategy").(string)
+
+	for _, pipeline := range existing {
+		if !isUnmanagedPipeline(d, managed, pipeline.Name) {
+			continue
+		}
+
+		if pruneStrategy == "archive" {
+			if pipeline.Archived {
+				continue
+			}
+
+			_, err = team.ArchivePipeline(pipeline.Name)
+		} else {
+			_, err = team.DeletePipeline(pipeline.Name)
+		}
+		providerConfig.InvalidatePipelines(teamName)
+
+		if err != nil {
+			return append(diags, diag.Errorf(
+				"Error pruning (%s) pipeline %s from team '%s': %s",
+				pruneStrategy, pipeline.Name, teamName, err,
+			)...)
+		}
+	}
+
+	return append(diags, resourceTeamPipelinesRead(ctx, d, m)...)
+}
+
+func resourceTeamPipelinesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Id()
+
+	_, teamFound, err := providerConfig.FindTeam(teamName)
+	if err != nil {
+		return diag.Errorf("Error looking up team '%s': %s", teamName, err)
+	}
+
+	if !teamFound {
+		d.SetId("")
+		return nil
+	}
+
+	pipelines, err := teamPipelines(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	configHashes := map[string]interface{}{}
+	paused := map[string]interface{}{}
+	exposed := map[string]interface{}{}
+	managed := map[string]bool{}
+
+	for _, pipeline := range pipelines {
+		pipelineName := pipeline.Get("pipeline_name").(string)
+		managed[pipelineName] = true
+
+		current, found, err := readPipeline(ctx, providerConfig, teamName, pipelineName)
+		if err != nil {
+			return diag.Errorf(
+				"Error reading pipeline %s from team '%s': %s",
+				pipelineName, teamName, err,
+			)
+		}
+
+		if found {
+			configHashes[pipelineName] = current.ConfigHash
+			paused[pipelineName] = current.IsPaused
+			exposed[pipelineName] = current.IsExposed
+			diags = append(diags, pipelinePolicyWarnings(providerConfig, teamName, pipelineName, current.JSON)...)
+			diags = append(diags, pipelineSecretWarnings(pipeline, current.JSON)...)
+		}
+	}
+
+	existing, err := providerConfig.ListPipelines(teamName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unmanaged := []string{}
+	pruneStrategy := d.Get("prune_strategy").(string)
+
+	for _, pipeline := range existing {
+		if pipeline.Archived && pruneStrategy == "archive" {
+			continue
+		}
+
+		if isUnmanagedPipeline(d, managed, pipeline.Name) {
+			unmanaged = append(unmanaged, pipeline.Name)
+		}
+	}
+
+	d.Set("team_name", teamName)
+	d.Set("config_hashes", configHashes)
+	d.Set("paused_pipelines", paused)
+	d.Set("exposed_pipelines", exposed)
+	d.Set("unmanaged_pipelines", unmanaged)
+
+	return diags
+}
+
+func resourceTeamPipelinesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	teamName := d.Get("team_name").(string)
+	team := providerConfig.Client.Team(teamName)
+
+	pipelines, err := teamPipelines(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the managed pipelines are removed in the same way as unmanaged ones
+	// are pruned
+	pruneStrategy := d.Get("prune_strategy").(string)
+
+	for _, pipeline := range pipelines {
+		pipelineName := pipeline.Get("pipeline_name").(string)
+
+		if pruneStrategy == "archive" {
+			_, err = team.ArchivePipeline(pipelineName)
+		} else {
+			_, err = team.DeletePipeline(pipelineName)
+		}
+		providerConfig.InvalidatePipelines(teamName)
+
+		if err != nil {
+			return diag.Errorf(
+				"Could not %s pipeline %s from team %s: %s",
+				pruneStrategy, pipelineName, teamName, err,
+			)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestTeamPipelinesPrunesUnmanagedPipelines(t *testing.T) {
+	team := new(concoursefakes.FakeTeam)
+	team.ListPipelinesReturns([]atc.Pipeline{
+		{Name: "managed", TeamName: "main", Paused: true},
+		{Name: "orphaned", TeamName: "main"},
+		{Name: "child-deploy", TeamName: "main"},
+		{Name: "already-archived", TeamName: "main", Archived: true},
+	}, nil)
+	team.PipelineConfigReturns(atc.Config{}, "1", true, nil)
+	team.UnpausePipelineReturns(true, nil)
+
+	client := new(concoursefakes.FakeClient)
+	client.TeamReturns(team)
+	client.ListTeamsReturns([]atc.Team{{Name: "main"}}, nil)
+
+	d := schema.TestResourceDataRaw(t, resourceTeamPipelines().Schema, map[string]interface{}{
+		"team_name": "main",
+		"exclude":   []interface{}{"child-*"},
+		"pipeline": []interface{}{
+			map[string]interface{}{
+				"pipeline_name":          "managed",
+				"pipeline_config_format": "yaml",
+				"pipeline_config":        "jobs:\n- name: build\n  plan: [{get: repo}]\n",
+			},
+		},
+	})
+
+	diags := resourceTeamPipelinesCreateUpdate(context.Background(), d, &ProviderConfig{Client: client})
+	if diags.HasError() {
+		t.Fatalf("error applying team pipelines: %v", diags)
+	}
+
+	if team.CreateOrUpdatePipelineConfigCallCount() != 1 {
+		t.Fatalf("expected the managed pipeline's config to be set")
+	}
+
+	if name, _, _, _ := team.CreateOrUpdatePipelineConfigArgsForCall(0); name != "managed" {
+		t.Fatalf("expected config to be set for managed, got %s", name)
+	}
+
+	if team.UnpausePipelineCallCount() != 1 || team.HidePipelineCallCount() != 0 {
+		t.Fatalf("expected only the managed pipeline to be unpaused")
+	}
+
+	var archived []string
+	for i := 0; i < team.ArchivePipelineCallCount(); i++ {
+		archived = append(archived, team.ArchivePipelineArgsForCall(i))
+	}
+
+	if !reflect.DeepEqual(archived, []string{"orphaned"}) {
+		t.Fatalf("expected only orphaned to be archived, got %v", archived)
+	}
+
+	if team.DeletePipelineCallCount() != 0 {
+		t.Fatalf("expected no pipelines to be deleted")
+	}
+}
+
+func TestTeamPipelinesDeleteFollowsPruneStrategy(t *testing.T) {
+	for _, pruneStrategy := range pruneStrategies {
+		team := new(concoursefakes.FakeTeam)
+
+		client := new(concoursefakes.FakeClient)
+		client.TeamReturns(team)
+
+		d := schema.TestResourceDataRaw(t, resourceTeamPipelines().Schema, map[string]interface{}{
+			"team_name":      "main",
+			"prune_strategy": pruneStrategy,
+			"pipeline": []interface{}{
+				map[string]interface{}{
+					"pipeline_name":   "managed",
+					"pipeline_config": "jobs: []\n",
+				},
+			},
+		})
+
+		diags := resourceTeamPipelinesDelete(context.Background(), d, &ProviderConfig{Client: client})
+		if diags.HasError() {
+			t.Fatalf("prune_strategy %s: error deleting team pipelines: %v", pruneStrategy, diags)
+		}
+
+		archived, deleted := team.ArchivePipelineCallCount(), team.DeletePipelineCallCount()
+
+		if pruneStrategy == "archive" && (archived != 1 || deleted != 0) {
+			t.Fatalf("expected managed to be archived, got %d archived, %d deleted", archived, deleted)
+		}
+
+		if pruneStrategy == "delete" && (archived != 0 || deleted != 1) {
+			t.Fatalf("expected managed to be deleted, got %d archived, %d deleted", archived, deleted)
+		}
+	}
+}
+
+func TestTeamPipelinesDetectsPausedAndExposedDrift(t *testing.T) {
+	pipelineConfig := "jobs:\n- name: build\n  plan: [{get: repo}]\n"
+
+	// managed was unpaused and exposed outside of terraform
+	state := map[string]string{
+		"id":                         "main",
+		"team_name":                  "main",
+		"prune_strategy":             "archive",
+		"pipeline.#":                 "1",
+		"pipeline.0.pipeline_name":   "managed",
+		"pipeline.0.pipeline_config": pipelineConfig,
+		"pipeline.0.is_paused":       "true",
+		"pipeline.0.is_exposed":      "false",
+		"paused_pipelines.%":         "1",
+		"paused_pipelines.managed":   "false",
+		"exposed_pipelines.%":        "1",
+		"exposed_pipelines.managed":  "true",
+		"unmanaged_pipelines.#":      "0",
+	}
+
+	diffTeamPipelines := func() *terraform.InstanceDiff {
+		diff, err := resourceTeamPipelines().Diff(
+			context.Background(),
+			&terraform.InstanceState{ID: "main", Attributes: state},
+			terraform.NewResourceConfigRaw(map[string]interface{}{
+				"team_name": "main",
+				"pipeline": []interface{}{
+					map[string]interface{}{
+						"pipeline_name":          "managed",
+						"pipeline_config":        pipelineConfig,
+						"pipeline_config_format": "yaml",
+						"is_paused":              true,
+					},
+				},
+			}),
+			&ProviderConfig{DefaultTeam: "main"},
+		)
+		if err != nil {
+			t.Fatalf("error diffing team pipelines: %s", err)
+		}
+
+		if diff == nil {
+			t.Fatalf("expected a diff")
+		}
+
+		return diff
+	}
+
+	diff := diffTeamPipelines()
+
+	for key, expected := range map[string]bool{
+		"paused_pipelines.managed":  true,
+		"exposed_pipelines.managed": false,
+	} {
+		attr := diff.Attributes[key]
+		if attr == nil {
+			t.Fatalf("expected %s to change", key)
+		}
+
+		if actual, _ := strconv.ParseBool(attr.New); actual != expected {
+			t.Fatalf("expected %s to become %t, got %q", key, expected, attr.New)
+		}
+	}
+
+	state["paused_pipelines.managed"] = "true"
+	state["exposed_pipelines.managed"] = "false"
+
+	diff = diffTeamPipelines()
+	if diff.Attributes["paused_pipelines.managed"] != nil || diff.Attributes["exposed_pipelines.managed"] != nil {
+		t.Fatalf("expected no change without drift, got %v", diff.Attributes)
+	}
+}
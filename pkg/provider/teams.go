@@ -21,8 +21,7 @@ func dataTeams() *schema.Resource {
 }
 
 func dataTeamsReads(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*ProviderConfig).Client
-	teams, err := client.ListTeams()
+	teams, err := m.(*ProviderConfig).ListTeams()
 	if err != nil {
 		return diag.FromErr(err)
 	}
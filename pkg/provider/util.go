@@ -1,10 +1,15 @@
 package provider
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"github.com/concourse/concourse/atc"
 	"github.com/concourse/concourse/go-concourse/concourse"
 	"github.com/concourse/concourse/vars"
 	"github.com/ghodss/yaml"
+	"github.com/google/go-jsonnet"
+	yamlv2 "gopkg.in/yaml.v2"
 	"strings"
 )
 
@@ -25,6 +30,35 @@ func JSONToJSON(inputJSON string) (string, error) {
 	return string(outputJSON), nil
 }
 
+// NormalizePipelineConfig round trips JSON through atc.Config, so that it
+// can be compared with config read back from concourse
+func NormalizePipelineConfig(inputJSON string) (string, error) {
+	var config atc.Config
+
+	if err := json.Unmarshal([]byte(inputJSON), &config); err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+
+	if err != nil {
+		return "", err
+	}
+
+	return JSONToJSON(string(configJSON))
+}
+
+// HashPipelineConfig returns a SHA-256 hash of normalized pipeline config
+func HashPipelineConfig(inputJSON string) (string, error) {
+	normalizedJSON, err := NormalizePipelineConfig(inputJSON)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(normalizedJSON))), nil
+}
+
 // YAMLToJSON is just a wrapper for less type boilerplate
 func YAMLToJSON(inputYAML string) (string, error) {
 	outputJSON, err := yaml.YAMLToJSON([]byte(inputYAML))
@@ -47,12 +81,86 @@ func JSONToYAML(inputJSON string) (string, error) {
 	return string(outputYAML), nil
 }
 
+// ParseVars parses YAML (or JSON) of template variables, keeping the types
+// of their values so that they can be interpolated as lists, maps, booleans
+// and numbers
+func ParseVars(inputYAML string) (map[string]interface{}, error) {
+	parsedVars := map[string]interface{}{}
+
+	if err := yamlv2.Unmarshal([]byte(inputYAML), &parsedVars); err != nil {
+		return nil, err
+	}
+
+	return parsedVars, nil
+}
+
+// PipelineConfigOptions are the less common inputs to ParsePipelineConfig
+type PipelineConfigOptions struct {
+	// JsonnetPaths are searched, in order, for files imported by jsonnet
+	JsonnetPaths []string
+}
+
 // ParsePipelineConfig returns parsed/validated JSON
-// from either YAML or JSON
+// from either YAML, JSON or jsonnet. YAML may contain multiple documents,
+// which are merged by MergePipelineConfigs.
 func ParsePipelineConfig(
 	pipelineConfig string,
 	pipelineConfigFormat string,
 	inputVars map[string]interface{},
+	opts PipelineConfigOptions,
+) (string, error) {
+	var err error
+
+	// jsonnet is evaluated to JSON first, vars are then interpolated as usual
+	if pipelineConfigFormat == "jsonnet" {
+		pipelineConfig, err = EvaluateJsonnet(pipelineConfig, inputVars, opts.JsonnetPaths)
+		if err != nil {
+			return "", err
+		}
+
+		pipelineConfigFormat = "json"
+	}
+
+	if pipelineConfigFormat != "yaml" {
+		return parsePipelineConfigDocument(pipelineConfig, pipelineConfigFormat, inputVars)
+	}
+
+	documents, err := SplitYAMLDocuments(pipelineConfig)
+	if err != nil {
+		return "", err
+	}
+
+	// empty documents, e.g. from a leading "---", are left out
+	switch len(documents) {
+	case 0:
+		return parsePipelineConfigDocument(pipelineConfig, pipelineConfigFormat, inputVars)
+	case 1:
+		return parsePipelineConfigDocument(documents[0], pipelineConfigFormat, inputVars)
+	}
+
+	var fragmentsJSON []string
+
+	for i, document := range documents {
+		fragmentJSON, err := parsePipelineConfigDocument(document, pipelineConfigFormat, inputVars)
+		if err != nil {
+			return "", fmt.Errorf("document %d: %s", i+1, err)
+		}
+
+		fragmentsJSON = append(fragmentsJSON, fragmentJSON)
+	}
+
+	mergedJSON, err := MergePipelineConfigs(fragmentsJSON)
+	if err != nil {
+		return "", err
+	}
+
+	return JSONToJSON(mergedJSON)
+}
+
+func parsePipelineConfigDocument(
+	pipelineConfig string,
+	pipelineConfigFormat string,
+	inputVars map[string]interface{},
 ) (string, error) {
 	var err error
 	outputJSON := ""
@@ -84,6 +192,63 @@ func ParsePipelineConfig(
 	return outputJSON, nil
 }
 
+// EvaluateJsonnet evaluates a jsonnet program to JSON. Each of inputVars is
+// available through std.extVar, strings as strings and other values with
+// their types.
+func EvaluateJsonnet(
+	program string,
+	inputVars map[string]interface{},
+	libraryPaths []string,
+) (string, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{JPaths: libraryPaths})
+
+	for name, value := range inputVars {
+		if str, ok := value.(string); ok {
+			vm.ExtVar(name, str)
+			continue
+		}
+
+		code, err := json.Marshal(stringKeys(value))
+		if err != nil {
+			return "", fmt.Errorf("Error passing var %s to jsonnet: %s", name, err)
+		}
+
+		vm.ExtCode(name, string(code))
+	}
+
+	return vm.EvaluateAnonymousSnippet("pipeline_config", program)
+}
+
+// stringKeys converts maps decoded from YAML, which have interface{} keys,
+// so that they can be encoded as JSON
+func stringKeys(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range typedValue {
+			converted[fmt.Sprintf("%v", k)] = stringKeys(v)
+		}
+		return converted
+
+	case map[string]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range typedValue {
+			converted[k] = stringKeys(v)
+		}
+		return converted
+
+	case []interface{}:
+		converted := make([]interface{}, len(typedValue))
+		for i, v := range typedValue {
+			converted[i] = stringKeys(v)
+		}
+		return converted
+	}
+
+	return value
+}
+
 func SerializeWarnings(warnings []concourse.ConfigWarning) string {
 	var warningsMsg strings.Builder
 	if len(warnings) > 0 {
@@ -95,3 +260,37 @@ func SerializeWarnings(warnings []concourse.ConfigWarning) string {
 
 	return warningsMsg.String()
 }
+
+// nameMatches is whether name matches pattern, exactly, or by prefix if the
+// pattern ends with "*". Every pattern of names in the provider, such as
+// allowed_unresolved or exclude, is matched this way.
+func nameMatches(name string, pattern string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return name == pattern
+}
+
+// nameAllowed is whether name matches any of allowed, see nameMatches
+func nameAllowed(name string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if nameMatches(name, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateNamePattern checks that "*" is only used at the end of a pattern,
+// see nameMatches
+func validateNamePattern(value interface{}, key string) ([]string, []error) {
+	if strings.Contains(strings.TrimSuffix(value.(string), "*"), "*") {
+		return nil, []error{fmt.Errorf(
+			"%s can only use \"*\" at the end, to match a prefix: %q", key, value,
+		)}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParsePipelineConfigWithTypedVars(t *testing.T) {
+	inputVars, err := ParseVars(`
+branches: [main, develop]
+enabled: true
+git:
+  uri: https://example.com/repo.git
+  depth: 1
+`)
+
+	if err != nil {
+		t.Fatalf("error parsing vars: %s", err)
+	}
+
+	pipelineConfig := `
+resources:
+- name: repo
+  type: git
+  source:
+    uri: ((git.uri))
+    depth: ((git.depth))
+    branches: ((branches))
+    disable_ci_skip: ((enabled))
+    tag_filter: v((git.depth)).*
+`
+
+	expected := `{"resources":[{"name":"repo","source":{"branches":["main","develop"],"depth":1,"disable_ci_skip":true,"tag_filter":"v1.*","uri":"https://example.com/repo.git"},"type":"git"}]}`
+
+	actual, err := ParsePipelineConfig(pipelineConfig, "yaml", inputVars, PipelineConfigOptions{})
+
+	if err != nil {
+		t.Fatalf("error parsing pipeline config: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("\n\nexpected:\n\n%s\n\ngot:\n\n%s\n\n", expected, actual)
+	}
+}
+
+func TestParsePipelineConfigFromJsonnet(t *testing.T) {
+	inputVars := map[string]interface{}{
+		"location": "Europe/London",
+		"jobs":     []interface{}{"first", "second"},
+	}
+
+	pipelineConfig := `
+{
+  resources: [{
+    name: "every-midnight",
+    type: "time",
+    source: { location: std.extVar("location"), start: "((start))" },
+  }],
+  jobs: [
+    { name: job, plan: [{ get: "every-midnight" }] }
+    for job in std.extVar("jobs")
+  ],
+}
+`
+
+	expected := `{"jobs":[{"name":"first","plan":[{"get":"every-midnight"}]},{"name":"second","plan":[{"get":"every-midnight"}]}],"resources":[{"name":"every-midnight","source":{"location":"Europe/London","start":"12:00AM"},"type":"time"}]}`
+
+	inputVars["start"] = "12:00AM"
+
+	actual, err := ParsePipelineConfig(pipelineConfig, "jsonnet", inputVars, PipelineConfigOptions{})
+
+	if err != nil {
+		t.Fatalf("error parsing pipeline config: %s", err)
+	}
+
+	if expected != actual {
+		t.Fatalf("\n\nexpected:\n\n%s\n\ngot:\n\n%s\n\n", expected, actual)
+	}
+}
+
+func TestValidateNamePattern(t *testing.T) {
+	for pattern, valid := range map[string]bool{
+		"build":      true,
+		"deploy-*":   true,
+		"*":          true,
+		"*-prod":     false,
+		"deploy-*-a": false,
+	} {
+		if _, errs := validateNamePattern(pattern, "pipelines"); (len(errs) == 0) != valid {
+			t.Fatalf("pattern %q: expected valid %t, got %v", pattern, valid, errs)
+		}
+	}
+}
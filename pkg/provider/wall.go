@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/alphagov/terraform-provider-concourse/pkg/client"
+)
+
+// wallID is the ID of the wall, of which each concourse has one
+const wallID = "wall"
+
+func dataWall() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataWallRead,
+		Schema: map[string]*schema.Schema{
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"ttl": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"expires_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceWall() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWallCreateUpdate,
+		ReadContext:   resourceWallRead,
+		UpdateContext: resourceWallCreateUpdate,
+		DeleteContext: resourceWallDelete,
+
+		CustomizeDiff: resourceWallCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"message": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"ttl": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+
+			"expires_at": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func validateDuration(value interface{}, key string) ([]string, []error) {
+	duration, err := time.ParseDuration(value.(string))
+	if err != nil || duration <= 0 {
+		return nil, []error{fmt.Errorf("%s is not a positive duration, e.g. 2h30m: %q", key, value)}
+	}
+
+	return nil, nil
+}
+
+// wallExpiry returns when a wall with the given time left expires, or "" if
+// it does not
+func wallExpiry(ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+
+	return time.Now().Add(ttl).UTC().Format(time.RFC3339)
+}
+
+func readWall(providerConfig *ProviderConfig) (atc.Wall, error) {
+	wall, found, err := client.GetWall(providerConfig.Client)
+	if err != nil {
+		return wall, fmt.Errorf("Error reading wall: %s", err)
+	}
+
+	if !found {
+		return wall, fmt.Errorf("Error reading wall: concourse does not support a wall")
+	}
+
+	return wall, nil
+}
+
+func dataWallRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	wall, err := readWall(m.(*ProviderConfig))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ttl := ""
+	if wall.TTL > 0 {
+		ttl = wall.TTL.Round(time.Second).String()
+	}
+
+	d.SetId(wallID)
+	d.Set("message", wall.Message)
+	d.Set("ttl", ttl)
+	d.Set("expires_at", wallExpiry(wall.TTL))
+
+	return nil
+}
+
+func resourceWallCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// setting the wall again restarts its ttl
+	if d.Id() != "" && (d.HasChange("message") || d.HasChange("ttl")) {
+		return d.SetNewComputed("expires_at")
+	}
+
+	return nil
+}
+
+func resourceWallCreateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+
+	wall := atc.Wall{Message: d.Get("message").(string)}
+
+	if ttl := d.Get("ttl").(string); ttl != "" {
+		duration, err := time.ParseDuration(ttl)
+		if err != nil {
+			return diag.Errorf("Error parsing ttl of wall: %s", err)
+		}
+
+		wall.TTL = duration
+	}
+
+	found, err := client.SetWall(providerConfig.Client, wall)
+	if err != nil {
+		return diag.Errorf("Error setting wall: %s", err)
+	}
+
+	if !found {
+		return diag.Errorf("Error setting wall: concourse does not support a wall")
+	}
+
+	d.SetId(wallID)
+	d.Set("expires_at", wallExpiry(wall.TTL))
+
+	return resourceWallRead(ctx, d, m)
+}
+
+func resourceWallRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	wall, err := readWall(m.(*ProviderConfig))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if wall.Message == "" {
+		// a wall which has expired as intended is not set again
+		expiresAt, err := time.Parse(time.RFC3339, d.Get("expires_at").(string))
+		if err == nil && time.Now().After(expiresAt) {
+			return nil
+		}
+
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("message", wall.Message)
+
+	if d.Get("expires_at").(string) == "" {
+		d.Set("expires_at", wallExpiry(wall.TTL))
+	}
+
+	return nil
+}
+
+func resourceWallDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+
+	if _, err := client.ClearWall(providerConfig.Client); err != nil {
+		return diag.Errorf("Error clearing wall: %s", err)
+	}
+
+	d.SetId("")
+	return nil
+}
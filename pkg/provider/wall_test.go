@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse"
+)
+
+func TestWall(t *testing.T) {
+	var wall atc.Wall
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/wall" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(wall)
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&wall)
+		case http.MethodDelete:
+			wall = atc.Wall{}
+		}
+	}))
+	defer server.Close()
+
+	providerConfig := &ProviderConfig{
+		Client: concourse.NewClient(server.URL, http.DefaultClient, false),
+	}
+
+	d := resourceWall().TestResourceData()
+	d.Set("message", "maintenance at 18:00")
+	d.Set("ttl", "2h")
+
+	if diags := resourceWallCreateUpdate(context.Background(), d, providerConfig); diags.HasError() {
+		t.Fatalf("error setting wall: %v", diags)
+	}
+
+	if wall.Message != "maintenance at 18:00" || wall.TTL != 2*time.Hour {
+		t.Fatalf("expected the wall to be set with its ttl, got %+v", wall)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, d.Get("expires_at").(string))
+	if err != nil || expiresAt.Before(time.Now().Add(time.Hour)) {
+		t.Fatalf("expected the wall to expire in 2h, got %q", d.Get("expires_at"))
+	}
+
+	data := dataWall().TestResourceData()
+	if diags := dataWallRead(context.Background(), data, providerConfig); diags.HasError() {
+		t.Fatalf("error reading wall: %v", diags)
+	}
+
+	if data.Get("message") != "maintenance at 18:00" || data.Get("ttl") != "2h0m0s" {
+		t.Fatalf("expected the data source to read the wall, got %q %q", data.Get("message"), data.Get("ttl"))
+	}
+
+	// a wall which has expired as intended is kept in state
+	wall = atc.Wall{}
+	d.Set("expires_at", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+
+	if diags := resourceWallRead(context.Background(), d, providerConfig); diags.HasError() || d.Id() == "" {
+		t.Fatalf("expected an expired wall to be kept in state, got %v", diags)
+	}
+
+	// a wall which was cleared early is set again
+	d.Set("expires_at", time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+
+	if diags := resourceWallRead(context.Background(), d, providerConfig); diags.HasError() || d.Id() != "" {
+		t.Fatalf("expected a cleared wall to be removed from state, got %v", diags)
+	}
+
+	wall = atc.Wall{Message: "left over"}
+	d.SetId(wallID)
+
+	if diags := resourceWallDelete(context.Background(), d, providerConfig); diags.HasError() {
+		t.Fatalf("error clearing wall: %v", diags)
+	}
+
+	if wall.Message != "" {
+		t.Fatalf("expected the wall to be cleared, got %+v", wall)
+	}
+}
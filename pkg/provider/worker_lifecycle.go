@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/alphagov/terraform-provider-concourse/pkg/client"
+)
+
+// workerPollInterval is how often workers are listed while waiting for one
+// to reach the state targeted by its lifecycle action
+var workerPollInterval = 5 * time.Second
+
+// workerGone is the state of a worker which no longer exists
+const workerGone = "gone"
+
+var workerLifecycleActions = []string{
+	"land",
+	"retire",
+	"prune",
+}
+
+// workerLifecycleTargets maps each lifecycle action to the states in which
+// it has finished
+var workerLifecycleTargets = map[string][]string{
+	"land":   {"landed", workerGone},
+	"retire": {workerGone},
+	"prune":  {workerGone},
+}
+
+var workerActionVerbs = map[string]string{
+	"land":   "landing",
+	"retire": "retiring",
+	"prune":  "pruning",
+}
+
+func resourceWorkerLifecycle() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWorkerLifecycleCreate,
+		ReadContext:   resourceWorkerLifecycleRead,
+		DeleteContext: resourceWorkerLifecycleDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"worker_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"action": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(workerLifecycleActions, false)),
+			},
+
+			"wait": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				ForceNew: true,
+			},
+
+			"triggers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			"state": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// workerState returns the state of a worker, or workerGone if it does not
+// exist
+func workerState(providerConfig *ProviderConfig, workerName string) (string, error) {
+	workers, err := providerConfig.Client.ListWorkers()
+	if err != nil {
+		return "", err
+	}
+
+	for _, worker := range workers {
+		if worker.Name == workerName {
+			return worker.State, nil
+		}
+	}
+
+	return workerGone, nil
+}
+
+func resourceWorkerLifecycleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	providerConfig := m.(*ProviderConfig)
+	workerName := d.Get("worker_name").(string)
+	action := d.Get("action").(string)
+	targets := workerLifecycleTargets[action]
+
+	state, err := workerState(providerConfig, workerName)
+	if err != nil {
+		return diag.Errorf("Error looking up worker %s: %s", workerName, err)
+	}
+
+	if state == workerGone && action == "land" {
+		return diag.Errorf("Could not find worker %s to land", workerName)
+	}
+
+	if !stringInList(state, targets) {
+		switch action {
+		case "land":
+			err = providerConfig.Client.LandWorker(workerName)
+		case "retire":
+			_, err = client.RetireWorker(providerConfig.Client, workerName)
+		case "prune":
+			err = providerConfig.Client.PruneWorker(workerName)
+		}
+
+		if err != nil {
+			return diag.Errorf("Error %s worker %s: %s", workerActionVerbs[action], workerName, err)
+		}
+
+		if d.Get("wait").(bool) {
+			state, err = waitForWorker(ctx, providerConfig, workerName, targets, d.Timeout(schema.TimeoutCreate))
+		} else {
+			state, err = workerState(providerConfig, workerName)
+		}
+
+		if err != nil {
+			return diag.Errorf("Error waiting for worker %s: %s", workerName, err)
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", workerName, action))
+	d.Set("state", state)
+
+	return nil
+}
+
+// waitForWorker polls a worker until it is in one of the target states
+func waitForWorker(
+	ctx context.Context,
+	providerConfig *ProviderConfig,
+	workerName string,
+	targets []string,
+	timeout time.Duration,
+) (string, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		state, err := workerState(providerConfig, workerName)
+		if err != nil {
+			return state, err
+		}
+
+		if stringInList(state, targets) {
+			return state, nil
+		}
+
+		if time.Now().After(deadline) {
+			return state, fmt.Errorf("worker is still %s after %s", state, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-time.After(workerPollInterval):
+		}
+	}
+}
+
+func resourceWorkerLifecycleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// a lifecycle action is a one off, which is only repeated when its
+	// arguments or triggers change, e.g. when the worker is replaced
+	return nil
+}
+
+func resourceWorkerLifecycleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
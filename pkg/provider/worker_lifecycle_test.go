@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWorkerLifecycleWaitsForWorker(t *testing.T) {
+	pollInterval := workerPollInterval
+	t.Cleanup(func() { workerPollInterval = pollInterval })
+	workerPollInterval = 0
+
+	client := new(concoursefakes.FakeClient)
+	client.ListWorkersReturnsOnCall(0, []atc.Worker{{Name: "worker-1", State: "running"}}, nil)
+	client.ListWorkersReturnsOnCall(1, []atc.Worker{{Name: "worker-1", State: "landing"}}, nil)
+	client.ListWorkersReturnsOnCall(2, []atc.Worker{{Name: "worker-1", State: "landed"}}, nil)
+
+	d := schema.TestResourceDataRaw(t, resourceWorkerLifecycle().Schema, map[string]interface{}{
+		"worker_name": "worker-1",
+		"action":      "land",
+	})
+
+	if diags := resourceWorkerLifecycleCreate(context.Background(), d, &ProviderConfig{Client: client}); diags.HasError() {
+		t.Fatalf("error landing worker: %v", diags)
+	}
+
+	if client.LandWorkerCallCount() != 1 || client.LandWorkerArgsForCall(0) != "worker-1" {
+		t.Fatalf("expected worker-1 to be landed once")
+	}
+
+	if client.ListWorkersCallCount() != 3 || d.Get("state") != "landed" || d.Id() != "worker-1:land" {
+		t.Fatalf("expected to poll the worker until it landed, got %q", d.Get("state"))
+	}
+}
+
+func TestWorkerLifecycleSkipsFinishedAction(t *testing.T) {
+	client := new(concoursefakes.FakeClient)
+	client.ListWorkersReturns([]atc.Worker{{Name: "worker-2", State: "running"}}, nil)
+
+	d := schema.TestResourceDataRaw(t, resourceWorkerLifecycle().Schema, map[string]interface{}{
+		"worker_name": "worker-1",
+		"action":      "prune",
+	})
+
+	if diags := resourceWorkerLifecycleCreate(context.Background(), d, &ProviderConfig{Client: client}); diags.HasError() {
+		t.Fatalf("error pruning worker: %v", diags)
+	}
+
+	if client.PruneWorkerCallCount() != 0 || d.Get("state") != workerGone {
+		t.Fatalf("expected a worker which is already gone not to be pruned")
+	}
+}